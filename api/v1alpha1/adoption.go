@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// DefaultClusterName and DefaultAdoptionPolicy are the cluster name and the adoption policy of the default
+	// S3UserClass when there's no S3UserClass object with its name
+	DefaultClusterName    string
+	DefaultAdoptionPolicy AdoptionPolicy
+)
+
+// Only alphanumeric characters and underscore are allowed for tenant name
+var k8sNameSpecialChars = regexp.MustCompile(`[.-]`)
+
+// CephTenant returns the Ceph tenant of the namespace in the Ceph cluster
+func CephTenant(clusterName, namespace string) string {
+	return fmt.Sprintf("%s__%s",
+		k8sNameSpecialChars.ReplaceAllString(clusterName, "_"),
+		k8sNameSpecialChars.ReplaceAllString(namespace, "_"))
+}
+
+// CanAdoptExistingUser reports whether the s3UserClaim can adopt its existing user. The users of the tenant of its
+// namespace can always be adopted, the users of other tenants only if the adoption policy of its class allows its
+// namespace.
+func CanAdoptExistingUser(ctx context.Context, reader client.Reader, suc *S3UserClaim) (bool, error) {
+	spec, err := getS3UserClassSpec(ctx, reader, suc)
+	if err != nil {
+		return false, err
+	}
+	// A user without tenant belongs to the empty tenant
+	tenant, _, found := strings.Cut(suc.Spec.ExistingUser, "$")
+	if !found {
+		tenant = ""
+	}
+	return tenant == CephTenant(spec.ClusterName, suc.Namespace) || spec.Adoption.Allows(suc.Namespace), nil
+}
+
+// FindAdoptingS3UserClaim returns the namespaced name of another s3UserClaim of the same class which adopts the
+// existing user of the s3UserClaim, if any
+func FindAdoptingS3UserClaim(ctx context.Context, reader client.Reader, suc *S3UserClaim) (string, error) {
+	s3UserClaimList := &S3UserClaimList{}
+	if err := reader.List(ctx, s3UserClaimList); err != nil {
+		return "", fmt.Errorf("failed to list s3UserClaims, %w", err)
+	}
+	s3UserClassName := s3UserClaimClassName(suc)
+	for _, s3UserClaim := range s3UserClaimList.Items {
+		if s3UserClaim.Namespace == suc.Namespace && s3UserClaim.Name == suc.Name {
+			continue
+		}
+		if s3UserClaim.Spec.ExistingUser == suc.Spec.ExistingUser &&
+			s3UserClaimClassName(&s3UserClaim) == s3UserClassName {
+			return fmt.Sprintf("%s/%s", s3UserClaim.Namespace, s3UserClaim.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// s3UserClaimClassName returns the name of the class of the s3UserClaim, which is the default class if it's empty
+func s3UserClaimClassName(suc *S3UserClaim) string {
+	if suc.Spec.S3UserClass == "" {
+		return DefaultS3UserClass
+	}
+	return suc.Spec.S3UserClass
+}
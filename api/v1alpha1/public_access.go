@@ -0,0 +1,44 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultPublicAccessPolicy is the public access policy of the default S3UserClass when there's no S3UserClass
+// object with its name
+var DefaultPublicAccessPolicy PublicAccessPolicy
+
+// GetPublicAccessPolicy returns the public access policy of the s3UserClaim's class
+func GetPublicAccessPolicy(ctx context.Context, reader client.Reader, suc *S3UserClaim) (*PublicAccessPolicy, error) {
+	spec, err := getS3UserClassSpec(ctx, reader, suc)
+	if err != nil {
+		return nil, err
+	}
+	return spec.PublicAccess, nil
+}
+
+// getS3UserClassSpec returns the spec of the s3UserClaim's class. The default class without an S3UserClass object
+// with its name is made of the defaults of the operator config.
+func getS3UserClassSpec(ctx context.Context, reader client.Reader, suc *S3UserClaim) (*S3UserClassSpec, error) {
+	s3UserClassName := suc.Spec.S3UserClass
+	if s3UserClassName == "" {
+		s3UserClassName = DefaultS3UserClass
+	}
+	s3UserClass := &S3UserClass{}
+	switch err := reader.Get(ctx, types.NamespacedName{Name: s3UserClassName}, s3UserClass); {
+	case apierrors.IsNotFound(err) && s3UserClassName == DefaultS3UserClass:
+		return &S3UserClassSpec{
+			ClusterName:  DefaultClusterName,
+			PublicAccess: DefaultPublicAccessPolicy.DeepCopy(),
+			Adoption:     DefaultAdoptionPolicy.DeepCopy(),
+		}, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get s3UserClass, %w", err)
+	}
+	return &s3UserClass.Spec, nil
+}
@@ -0,0 +1,223 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// QuotaEnforcementPolicy defines how the quotas of the S3UserClaims are enforced against the hard limits of the
+// ResourceQuotas of their namespace and the quotas of their team
+// +kubebuilder:object:generate=false
+type QuotaEnforcementPolicy struct {
+	// Mode is either requested, which compares the sum of the requested quotas to the hard limits, or overcommit,
+	// which compares the actual usage of the Ceph users to the hard limits
+	Mode string
+	// OvercommitRatio is the multiple of the hard limits which the requested quotas can sum up to in the overcommit mode
+	OvercommitRatio float64
+	// WarningThresholdPercent is the percentage of a hard limit whose consumption raises warnings, zero disables them
+	WarningThresholdPercent int64
+}
+
+// QuotaEnforcement is the quota enforcement policy of the operator
+var QuotaEnforcement = QuotaEnforcementPolicy{Mode: consts.QuotaModeRequested, OvercommitRatio: 1}
+
+var s3ResourceNames = []v1.ResourceName{
+	consts.ResourceNameS3MaxSize, consts.ResourceNameS3MaxObjects, consts.ResourceNameS3MaxBuckets,
+}
+
+// IsOvercommit reports whether the hard limits are enforced against the actual usage
+func (p QuotaEnforcementPolicy) IsOvercommit() bool {
+	return p.Mode == consts.QuotaModeOvercommit
+}
+
+// exceedsRequested checks the requested quotas against the hard limits, which are multiplied by the overcommit ratio
+// in the overcommit mode. The resources without a hard limit aren't limited.
+func (p QuotaEnforcementPolicy) exceedsRequested(hard v1.ResourceList, requested *UserQuota) bool {
+	ratio := 1.0
+	if p.IsOvercommit() {
+		ratio = p.OvercommitRatio
+	}
+	requestedList := quotaToResourceList(requested)
+	for _, resourceName := range s3ResourceNames {
+		limit, ok := hard[resourceName]
+		if !ok {
+			continue
+		}
+		if ratio != 1 {
+			limit = *resource.NewQuantity(int64(limit.AsApproximateFloat64()*ratio), limit.Format)
+		}
+		if amount := requestedList[resourceName]; amount.Cmp(limit) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// exceedsUsage checks the actual usage against the hard limits in the overcommit mode
+func (p QuotaEnforcementPolicy) exceedsUsage(hard v1.ResourceList, usage *UserQuota) bool {
+	if !p.IsOvercommit() || usage == nil {
+		return false
+	}
+	usageList := quotaToResourceList(usage)
+	for _, resourceName := range s3ResourceNames {
+		if limit, ok := hard[resourceName]; ok {
+			if amount := usageList[resourceName]; amount.Cmp(limit) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// thresholdWarnings returns a warning for each resource whose consumption reached the warning threshold of its hard
+// limit. The consumption is the sum of the requested quotas in the requested mode and the actual usage in the
+// overcommit mode.
+func (p QuotaEnforcementPolicy) thresholdWarnings(scope, name string, hard v1.ResourceList,
+	requested, usage *UserQuota) []string {
+	consumption := requested
+	if p.IsOvercommit() {
+		consumption = usage
+	}
+	consumptionList := quotaToResourceList(consumption)
+
+	var warnings []string
+	for _, resourceName := range s3ResourceNames {
+		limit, ok := hard[resourceName]
+		if !ok || limit.IsZero() {
+			continue
+		}
+		amount := consumptionList[resourceName]
+		percent := int64(amount.AsApproximateFloat64() * 100 / limit.AsApproximateFloat64())
+		if percent >= p.WarningThresholdPercent {
+			warnings = append(warnings, fmt.Sprintf("%s %s consumes %d%% of its %s quota", scope, name, percent,
+				resourceName))
+		}
+	}
+	return warnings
+}
+
+// CheckQuotaThresholds returns a warning for each resource of the namespace and the team of the s3UserClaim whose
+// consumption reached the warning threshold of QuotaEnforcement, including the quota of the s3UserClaim itself
+func CheckQuotaThresholds(ctx context.Context, runtimeClient client.Client, uncachedReader client.Reader,
+	suc *S3UserClaim) ([]string, error) {
+	if QuotaEnforcement.WarningThresholdPercent <= 0 {
+		return nil, nil
+	}
+
+	classQuotas := NewClassQuotas(uncachedReader)
+	namespaceRequested, err := CalculateNamespaceUsedQuota(ctx, uncachedReader, classQuotas, suc, suc.Namespace, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate namespace used quota, %w", err)
+	}
+	namespaceUsage, err := CalculateNamespaceUsage(ctx, uncachedReader, suc, suc.Namespace, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate namespace usage, %w", err)
+	}
+	resourceQuotaList := &v1.ResourceQuotaList{}
+	if err := runtimeClient.List(ctx, resourceQuotaList, client.InNamespace(suc.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list resource quotas, %w", err)
+	}
+	var warnings []string
+	for _, quota := range resourceQuotaList.Items {
+		warnings = append(warnings, QuotaEnforcement.thresholdWarnings("namespace", suc.Namespace, quota.Spec.Hard,
+			namespaceRequested, namespaceUsage)...)
+	}
+
+	teamQuotas, err := GetTeamQuotas(ctx, runtimeClient, suc.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team quotas, %w", err)
+	}
+	for i := range teamQuotas {
+		teamQuota := &teamQuotas[i]
+		teamRequested, err := CalculateClusterUsedQuota(ctx, uncachedReader, classQuotas, teamQuota, suc, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate cluster resource used quota, %w", err)
+		}
+		teamUsage, err := CalculateClusterUsage(ctx, uncachedReader, teamQuota, suc, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate cluster resource usage, %w", err)
+		}
+		warnings = append(warnings, QuotaEnforcement.thresholdWarnings("team", teamQuota.Name, teamQuota.Hard,
+			teamRequested, teamUsage)...)
+	}
+	return warnings, nil
+}
+
+// CalculateNamespaceUsage sums the actual usage of the s3UserClaims of the namespace as last synced from RGW
+func CalculateNamespaceUsage(ctx context.Context, uncachedReader client.Reader, suc *S3UserClaim, namespace string,
+	addCurrentUsage bool) (*UserQuota, error) {
+	totalUsage := UserQuota{}
+	s3UserClaimList := &S3UserClaimList{}
+	if err := uncachedReader.List(ctx, s3UserClaimList, client.InNamespace(namespace)); err != nil {
+		return &totalUsage, fmt.Errorf("failed to list s3 user claims, %w", err)
+	}
+	for _, claim := range s3UserClaimList.Items {
+		if claim.Name != suc.Name {
+			addS3UserClaimUsage(&totalUsage, &claim)
+		}
+	}
+	if addCurrentUsage {
+		addS3UserClaimUsage(&totalUsage, suc)
+	}
+	return &totalUsage, nil
+}
+
+// CalculateClusterUsage sums the actual usage of the s3UserClaims of the namespaces of the team quota as last synced
+// from RGW
+func CalculateClusterUsage(ctx context.Context, uncachedReader client.Reader, teamQuota *TeamQuota, suc *S3UserClaim,
+	addCurrentUsage bool) (*UserQuota, error) {
+	usages, err := CalculateClusterUsageByNamespace(ctx, uncachedReader, teamQuota, suc, addCurrentUsage)
+	if err != nil {
+		return &UserQuota{}, err
+	}
+	return sumUserQuotas(usages), nil
+}
+
+// CalculateClusterUsageByNamespace sums the actual usage of the s3UserClaims of each namespace of the team quota as
+// last synced from RGW
+func CalculateClusterUsageByNamespace(ctx context.Context, uncachedReader client.Reader, teamQuota *TeamQuota,
+	suc *S3UserClaim, addCurrentUsage bool) (map[string]*UserQuota, error) {
+	usages := make(map[string]*UserQuota, len(teamQuota.Namespaces))
+	for _, ns := range teamQuota.Namespaces {
+		usage := &UserQuota{}
+		usages[ns] = usage
+		s3UserClaimList := &S3UserClaimList{}
+		if err := uncachedReader.List(ctx, s3UserClaimList, client.InNamespace(ns)); err != nil {
+			return usages, fmt.Errorf("failed to list s3UserClaims, %w", err)
+		}
+		for _, claim := range s3UserClaimList.Items {
+			if claim.Name != suc.Name || claim.Namespace != suc.Namespace {
+				addS3UserClaimUsage(usage, &claim)
+			}
+		}
+	}
+	if addCurrentUsage {
+		addS3UserClaimUsage(namespaceQuota(usages, suc.Namespace), suc)
+	}
+	return usages, nil
+}
+
+// addS3UserClaimUsage adds the usage of the s3UserClaim, a claim whose usage isn't synced yet uses nothing
+func addS3UserClaimUsage(totalUsage *UserQuota, suc *S3UserClaim) {
+	usage := suc.Status.Usage
+	if usage == nil {
+		return
+	}
+	totalUsage.MaxSize.Add(usage.Size)
+	totalUsage.MaxObjects.Add(usage.Objects)
+	totalUsage.MaxBuckets += usage.Buckets
+}
+
+func quotaToResourceList(quota *UserQuota) v1.ResourceList {
+	return v1.ResourceList{
+		consts.ResourceNameS3MaxSize:    quota.MaxSize,
+		consts.ResourceNameS3MaxObjects: quota.MaxObjects,
+		consts.ResourceNameS3MaxBuckets: *resource.NewQuantity(quota.MaxBuckets, resource.DecimalSI),
+	}
+}
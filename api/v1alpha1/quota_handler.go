@@ -4,14 +4,85 @@ import (
 	"context"
 	"fmt"
 
-	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// DefaultS3UserClass is the class of the S3UserClaims which don't specify one
+	DefaultS3UserClass string
 
-	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+	// DefaultUserQuota is the quota of the S3UserClaims which specify neither a quota nor a class with a default quota
+	DefaultUserQuota = UserQuota{
+		MaxSize:    resource.MustParse("5368709120"),
+		MaxObjects: resource.MustParse("1000"),
+		MaxBuckets: 2,
+	}
 )
 
-func CalculateNamespaceUsedQuota(ctx context.Context, uncachedReader client.Reader,
+// GetS3UserClaimQuota returns the quota of the s3UserClaim. The quota of the spec takes precedence over
+// the default quota of the s3UserClaim's class, which takes precedence over DefaultUserQuota.
+func GetS3UserClaimQuota(ctx context.Context, reader client.Reader, suc *S3UserClaim) (*UserQuota, error) {
+	return NewClassQuotas(reader).S3UserClaimQuota(ctx, suc)
+}
+
+// ClassQuotas memoizes the default quotas of the S3UserClasses, so that summing the quotas of many s3UserClaims
+// fetches each class once. It's meant to live as long as a single aggregation.
+// +kubebuilder:object:generate=false
+type ClassQuotas struct {
+	reader client.Reader
+	quotas map[string]*UserQuota
+}
+
+func NewClassQuotas(reader client.Reader) *ClassQuotas {
+	return &ClassQuotas{reader: reader, quotas: map[string]*UserQuota{}}
+}
+
+// S3UserClaimQuota returns the quota of the s3UserClaim like GetS3UserClaimQuota, looking up its class only once
+func (cq *ClassQuotas) S3UserClaimQuota(ctx context.Context, suc *S3UserClaim) (*UserQuota, error) {
+	if suc.Spec.Quota != nil {
+		return suc.Spec.Quota, nil
+	}
+
+	s3UserClassName := suc.Spec.S3UserClass
+	if s3UserClassName == "" {
+		s3UserClassName = DefaultS3UserClass
+	}
+	if quota, ok := cq.quotas[s3UserClassName]; ok {
+		return quota, nil
+	}
+	quota := DefaultUserQuota.DeepCopy()
+	s3UserClass := &S3UserClass{}
+	switch err := cq.reader.Get(ctx, types.NamespacedName{Name: s3UserClassName}, s3UserClass); {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get s3UserClass, %w", err)
+	default:
+		if s3UserClass.Spec.DefaultQuota != nil {
+			quota = s3UserClass.Spec.DefaultQuota
+		}
+	}
+	cq.quotas[s3UserClassName] = quota
+	return quota, nil
+}
+
+func addS3UserClaimQuota(ctx context.Context, classQuotas *ClassQuotas, totalUsedQuota *UserQuota,
+	suc *S3UserClaim) error {
+	quota, err := classQuotas.S3UserClaimQuota(ctx, suc)
+	if err != nil {
+		return err
+	}
+	totalUsedQuota.MaxObjects.Add(quota.MaxObjects)
+	totalUsedQuota.MaxSize.Add(quota.MaxSize)
+	totalUsedQuota.MaxBuckets += quota.MaxBuckets
+	return nil
+}
+
+// CalculateNamespaceUsedQuota sums the quotas of the s3UserClaims of the namespace, looking up their classes in
+// classQuotas
+func CalculateNamespaceUsedQuota(ctx context.Context, uncachedReader client.Reader, classQuotas *ClassQuotas,
 	suc *S3UserClaim, namespace string, addCurrentQuota bool) (*UserQuota, error) {
 	totalUsedQuota := UserQuota{}
 	if suc == nil {
@@ -26,88 +97,79 @@ func CalculateNamespaceUsedQuota(ctx context.Context, uncachedReader client.Read
 	// Sum all resource requests
 	for _, claim := range s3UserClaimList.Items {
 		if claim.Name != suc.Name {
-			totalUsedQuota.MaxObjects.Add(claim.Spec.Quota.MaxObjects)
-			totalUsedQuota.MaxSize.Add(claim.Spec.Quota.MaxSize)
-			totalUsedQuota.MaxBuckets += claim.Spec.Quota.MaxBuckets
+			if err := addS3UserClaimQuota(ctx, classQuotas, &totalUsedQuota, &claim); err != nil {
+				return &totalUsedQuota, err
+			}
 		}
 	}
 	// Don't add the current user quota if the function is called by the cleaner
 	if addCurrentQuota {
-		totalUsedQuota.MaxObjects.Add(suc.Spec.Quota.MaxObjects)
-		totalUsedQuota.MaxSize.Add(suc.Spec.Quota.MaxSize)
-		totalUsedQuota.MaxBuckets += suc.Spec.Quota.MaxBuckets
+		if err := addS3UserClaimQuota(ctx, classQuotas, &totalUsedQuota, suc); err != nil {
+			return &totalUsedQuota, err
+		}
 	}
 	return &totalUsedQuota, nil
 }
 
-func CalculateClusterUsedQuota(ctx context.Context, runtimeClient client.Client,
-	suc *S3UserClaim, addCurrentQuota bool) (*UserQuota, string, error) {
-	totalClusterUsedQuota := UserQuota{}
-	// Find team's clusterResourceQuota
-	team, err := findTeam(ctx, runtimeClient, suc)
+// CalculateClusterUsedQuota sums the quotas of the s3UserClaims of the namespaces of the team quota, looking up their
+// classes in classQuotas
+func CalculateClusterUsedQuota(ctx context.Context, uncachedReader client.Reader, classQuotas *ClassQuotas,
+	teamQuota *TeamQuota, suc *S3UserClaim, addCurrentQuota bool) (*UserQuota, error) {
+	usedQuotas, err := CalculateClusterUsedQuotaByNamespace(ctx, uncachedReader, classQuotas, teamQuota, suc,
+		addCurrentQuota)
 	if err != nil {
-		return &totalClusterUsedQuota, "", fmt.Errorf("failed to find team, %w", err)
+		return &UserQuota{}, err
 	}
+	return sumUserQuotas(usedQuotas), nil
+}
 
+// CalculateClusterUsedQuotaByNamespace sums the quotas of the s3UserClaims of each namespace of the team quota, looking
+// up their classes in classQuotas
+func CalculateClusterUsedQuotaByNamespace(ctx context.Context, uncachedReader client.Reader, classQuotas *ClassQuotas,
+	teamQuota *TeamQuota, suc *S3UserClaim, addCurrentQuota bool) (map[string]*UserQuota, error) {
+	usedQuotas := make(map[string]*UserQuota, len(teamQuota.Namespaces))
 	// Sum all resource requests in team's namespaces
-	namespaces, err := findTeamNamespaces(ctx, runtimeClient, team)
-	if err != nil {
-		return &totalClusterUsedQuota, team, fmt.Errorf("failed to find team namespaces, %w", err)
-	}
-	for _, ns := range namespaces {
+	for _, ns := range teamQuota.Namespaces {
+		usedQuota := &UserQuota{}
+		usedQuotas[ns] = usedQuota
 		s3UserClaimList := &S3UserClaimList{}
 		if err := uncachedReader.List(ctx, s3UserClaimList, client.InNamespace(ns)); err != nil {
-			return &totalClusterUsedQuota, team, fmt.Errorf("failed to list s3UserClaims, %w", err)
+			return usedQuotas, fmt.Errorf("failed to list s3UserClaims, %w", err)
 		}
 
 		for _, claim := range s3UserClaimList.Items {
 			if claim.Name != suc.Name || claim.Namespace != suc.Namespace {
-				totalClusterUsedQuota.MaxObjects.Add(claim.Spec.Quota.MaxObjects)
-				totalClusterUsedQuota.MaxSize.Add(claim.Spec.Quota.MaxSize)
-				totalClusterUsedQuota.MaxBuckets += claim.Spec.Quota.MaxBuckets
+				if err := addS3UserClaimQuota(ctx, classQuotas, usedQuota, &claim); err != nil {
+					return usedQuotas, err
+				}
 			}
 		}
 	}
 	// Don't add the current user quota if the function is called by the cleaner
 	if addCurrentQuota {
-		totalClusterUsedQuota.MaxObjects.Add(suc.Spec.Quota.MaxObjects)
-		totalClusterUsedQuota.MaxSize.Add(suc.Spec.Quota.MaxSize)
-		totalClusterUsedQuota.MaxBuckets += suc.Spec.Quota.MaxBuckets
+		if err := addS3UserClaimQuota(ctx, classQuotas, namespaceQuota(usedQuotas, suc.Namespace), suc); err != nil {
+			return usedQuotas, err
+		}
 	}
-	return &totalClusterUsedQuota, team, nil
+	return usedQuotas, nil
 }
 
-func findTeam(ctx context.Context, runtimeClient client.Client, suc *S3UserClaim) (string, error) {
-	ns := &v1.Namespace{}
-	if err := runtimeClient.Get(ctx, types.NamespacedName{Name: suc.ObjectMeta.Namespace}, ns); err != nil {
-		return "", fmt.Errorf("failed to get namespace, %w", err)
-	}
-
-	team, ok := ns.ObjectMeta.Labels[consts.LabelTeam]
+// namespaceQuota returns the quota of the namespace in quotas, adding an empty one if it's missing
+func namespaceQuota(quotas map[string]*UserQuota, namespace string) *UserQuota {
+	quota, ok := quotas[namespace]
 	if !ok {
-		return "", fmt.Errorf("namespace %s doesn't have the team label: %s", ns.ObjectMeta.Name, consts.LabelTeam)
+		quota = &UserQuota{}
+		quotas[namespace] = quota
 	}
-
-	return team, nil
+	return quota
 }
 
-func findTeamNamespaces(ctx context.Context, runtimeClient client.Client, team string) ([]string, error) {
-	var namespaces []string
-
-	namespaceList := &v1.NamespaceList{}
-	if err := runtimeClient.List(ctx, namespaceList); err != nil {
-		return namespaces, fmt.Errorf("failed to list namespaces, %w", err)
+func sumUserQuotas(quotas map[string]*UserQuota) *UserQuota {
+	total := &UserQuota{}
+	for _, quota := range quotas {
+		total.MaxSize.Add(quota.MaxSize)
+		total.MaxObjects.Add(quota.MaxObjects)
+		total.MaxBuckets += quota.MaxBuckets
 	}
-
-	for _, ns := range namespaceList.Items {
-		labels := ns.ObjectMeta.Labels
-		if labels == nil {
-			labels = map[string]string{}
-		}
-		if nsTeam := labels[consts.LabelTeam]; nsTeam == team {
-			namespaces = append(namespaces, ns.ObjectMeta.Name)
-		}
-	}
-
-	return namespaces, nil
+	return total
 }
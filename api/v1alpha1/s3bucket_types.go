@@ -17,7 +17,14 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"encoding/json"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
 )
 
 // S3BucketSpec defines the desired state of S3Bucket
@@ -32,6 +39,49 @@ type S3BucketSpec struct {
 
 	// +kubebuilder:validation:Optional
 	S3SubuserBinding []SubuserBinding `json:"s3SubuserBinding,omitempty"`
+
+	// quota of the bucket which can't exceed the quota of the s3UserClaim
+	// +kubebuilder:validation:Optional
+	Quota *BucketQuota `json:"quota,omitempty"`
+
+	// versioning state of the bucket, the versioning isn't managed if it's not set
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Enabled;Suspended
+	Versioning string `json:"versioning,omitempty"`
+
+	// lifecycle configuration of the bucket. A configuration applied by the operator is removed once it's unset, a
+	// configuration set out of band is left untouched if it's not set.
+	// +kubebuilder:validation:Optional
+	Lifecycle *BucketLifecycle `json:"lifecycle,omitempty"`
+
+	// CORS configuration of the bucket. A configuration applied by the operator is removed once it's unset, a
+	// configuration set out of band is left untouched if it's not set.
+	// +kubebuilder:validation:Optional
+	CORS *BucketCORS `json:"cors,omitempty"`
+
+	// IAM policy statements which are appended to the statements generated for the subuser bindings and the grants
+	// of the bucket, e.g. to deny the requests from outside a network
+	// +kubebuilder:validation:Optional
+	AdditionalPolicyStatements []runtime.RawExtension `json:"additionalPolicyStatements,omitempty"`
+
+	// anonymous read access on the objects of the bucket, which must be allowed for the namespace by the
+	// S3UserClass of the s3UserRef
+	// +kubebuilder:validation:Optional
+	PublicAccess *BucketPublicAccess `json:"publicAccess,omitempty"`
+
+	// name of the bucket in Ceph which must follow the S3 bucket naming rules. Defaults to the name of the S3Bucket.
+	// +kubebuilder:validation:Optional
+	BucketName string `json:"bucketName,omitempty"`
+
+	// generates a unique name for the bucket in Ceph instead of using bucketName. The generated name is persisted in
+	// the s3.snappcloud.io/generated-bucket-name annotation.
+	// +kubebuilder:validation:Optional
+	GenerateBucketName *BucketNameGeneration `json:"generateBucketName,omitempty"`
+
+	// name of an existing bucket to import in the form of [tenant/]bucket. The bucket is linked to the user of the
+	// s3UserRef if another user owns it and keeps its name, which may differ from the name of the S3Bucket.
+	// +kubebuilder:validation:Optional
+	ExistingBucket string `json:"existingBucket,omitempty"`
 }
 
 // S3BucketStatus defines the observed state of S3Bucket
@@ -45,11 +95,51 @@ type S3BucketStatus struct {
 
 	// +kubebuilder:validation:Optional
 	Policy string `json:"policy,omitempty"`
+
+	// name of the bucket in Ceph
+	// +kubebuilder:validation:Optional
+	BucketName string `json:"bucketName,omitempty"`
+
+	// quota which is applied on the bucket
+	// +kubebuilder:validation:Optional
+	Quota *BucketQuota `json:"quota,omitempty"`
+
+	// versioning state of the bucket as observed on Ceph
+	// +kubebuilder:validation:Optional
+	Versioning string `json:"versioning,omitempty"`
+
+	// whether the lifecycle configuration of the bucket is applied by the operator
+	// +kubebuilder:validation:Optional
+	LifecycleApplied bool `json:"lifecycleApplied,omitempty"`
+
+	// whether the CORS configuration of the bucket is applied by the operator
+	// +kubebuilder:validation:Optional
+	CORSApplied bool `json:"corsApplied,omitempty"`
+
+	// import of the existing bucket, which is recorded once the bucket is imported
+	// +kubebuilder:validation:Optional
+	Import *BucketImportStatus `json:"import,omitempty"`
+
+	// generation of the S3Bucket which was last reconciled
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="S3USERREF",type=string,JSONPath=`.spec.s3UserRef`
+// +kubebuilder:printcolumn:name="READY",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="BUCKET",type=string,JSONPath=`.status.bucketName`,priority=1
+// +kubebuilder:printcolumn:name="VERSIONING",type=string,JSONPath=`.status.versioning`
+// +kubebuilder:printcolumn:name="MAX OBJECTS",type=string,JSONPath=`.status.quota.maxObjects`,priority=1
+// +kubebuilder:printcolumn:name="MAX SIZE",type=string,JSONPath=`.status.quota.maxSize`,priority=1
 // +kubebuilder:resource:shortName=s3b
 
 // S3 Bucket Instance
@@ -73,3 +163,70 @@ type S3BucketList struct {
 func init() {
 	SchemeBuilder.Register(&S3Bucket{}, &S3BucketList{})
 }
+
+// GetBucketName returns the name of the bucket in Ceph. An imported bucket keeps its own name. A generated name is
+// persisted in an annotation, the S3Buckets generated before only have it in their status. It's empty if the name
+// is to be generated and isn't generated yet.
+func (sb *S3Bucket) GetBucketName() string {
+	switch {
+	case sb.Spec.ExistingBucket != "":
+		return sb.Spec.ExistingBucket[strings.LastIndex(sb.Spec.ExistingBucket, "/")+1:]
+	case sb.Spec.BucketName != "":
+		return sb.Spec.BucketName
+	case sb.Spec.GenerateBucketName != nil:
+		if bucketName := sb.Annotations[consts.AnnotationGeneratedBucketName]; bucketName != "" {
+			return bucketName
+		}
+		return sb.Status.BucketName
+	}
+	return sb.Name
+}
+
+// PublicPolicyStatements returns the indices of the additional policy statements which allow anonymous users. Those
+// are the Allow statements whose Principal is "*" or {"AWS": "*"}, and the Allow statements with a NotPrincipal which
+// allow everyone but the given principals.
+func (sb *S3Bucket) PublicPolicyStatements() []int {
+	var indices []int
+	for i, rawStatement := range sb.Spec.AdditionalPolicyStatements {
+		statement := map[string]interface{}{}
+		if err := json.Unmarshal(rawStatement.Raw, &statement); err != nil {
+			continue
+		}
+		if isPublicPolicyStatement(statement) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+func isPublicPolicyStatement(statement map[string]interface{}) bool {
+	if statement["Effect"] != "Allow" {
+		return false
+	}
+	if statement["NotPrincipal"] != nil {
+		return true
+	}
+	switch principal := statement["Principal"].(type) {
+	case string:
+		return principal == "*"
+	case map[string]interface{}:
+		awsPrincipals, _ := stringOrStrings(principal["AWS"])
+		for _, awsPrincipal := range awsPrincipals {
+			if awsPrincipal == "*" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GenerateBucketName generates a new bucket name regarding the generateBucketName of the S3Bucket
+func (sb *S3Bucket) GenerateBucketName() string {
+	return sb.generateBucketName(rand.String(BucketNameRandomLength))
+}
+
+func (sb *S3Bucket) generateBucketName(random string) string {
+	replacer := strings.NewReplacer("{namespace}", sb.Namespace, "{name}", sb.Name)
+	return replacer.Replace(sb.Spec.GenerateBucketName.Prefix) + random +
+		replacer.Replace(sb.Spec.GenerateBucketName.Suffix)
+}
@@ -18,13 +18,19 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -34,6 +40,16 @@ import (
 // log is for logging in this package.
 var s3bucketlog = logf.Log.WithName("s3bucket-resource")
 
+// bucketNameRegex matches the names of 3 to 63 lowercase letters, digits, dots and hyphens which begin and end with a
+// letter or a digit
+var bucketNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// policyStatementElements are the elements of the IAM policy statements which are supported in bucket policies
+var policyStatementElements = map[string]bool{
+	"Sid": true, "Effect": true, "Principal": true, "NotPrincipal": true, "Action": true, "NotAction": true,
+	"Resource": true, "NotResource": true, "Condition": true,
+}
+
 func (sb *S3Bucket) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	runtimeClient = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
@@ -62,7 +78,17 @@ func (sb *S3Bucket) ValidateCreate() error {
 			allErrs,
 			field.Forbidden(field.NewPath("spec").Child("s3UserRef"), consts.S3UserRefNotFoundErrMessage),
 		)
+	} else {
+		allErrs = validateBucketQuota(ctx, sb, s3UserClaim, allErrs)
+		allErrs = validateSubuserBindings(sb, s3UserClaim, allErrs)
+		allErrs = validatePublicAccess(ctx, sb, s3UserClaim, allErrs)
+		allErrs = validatePublicPolicyStatements(ctx, sb, s3UserClaim, allErrs)
 	}
+	allErrs = validateLifecycle(sb.Spec.Lifecycle, allErrs)
+	allErrs = validateCORS(sb.Spec.CORS, allErrs)
+	allErrs = validateExistingBucket(sb.Spec.ExistingBucket, allErrs)
+	allErrs = validateBucketName(ctx, sb, allErrs)
+	allErrs = validatePolicyStatements(sb.Spec.AdditionalPolicyStatements, allErrs)
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -87,6 +113,74 @@ func (sb *S3Bucket) ValidateUpdate(old runtime.Object) error {
 			field.Forbidden(field.NewPath("spec").Child("s3UserRef"), consts.S3UserRefImmutableErrMessage),
 		)
 	}
+	if sb.Spec.ExistingBucket != oldS3Bucket.Spec.ExistingBucket {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(field.NewPath("spec").Child("existingBucket"), consts.ExistingBucketImmutableErrMessage),
+		)
+	}
+	if sb.Spec.BucketName != oldS3Bucket.Spec.BucketName {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(field.NewPath("spec").Child("bucketName"), consts.BucketNameImmutableErrMessage),
+		)
+	}
+	if !reflect.DeepEqual(sb.Spec.GenerateBucketName, oldS3Bucket.Spec.GenerateBucketName) {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(field.NewPath("spec").Child("generateBucketName"), consts.BucketNameImmutableErrMessage),
+		)
+	}
+	// The generated bucket name can only be set once, which is validated like the name given on creation
+	generatedBucketName := sb.Annotations[consts.AnnotationGeneratedBucketName]
+	oldGeneratedBucketName := oldS3Bucket.Annotations[consts.AnnotationGeneratedBucketName]
+	switch {
+	case oldGeneratedBucketName != "" && generatedBucketName != oldGeneratedBucketName:
+		allErrs = append(allErrs, field.Forbidden(
+			field.NewPath("metadata").Child("annotations").Key(consts.AnnotationGeneratedBucketName),
+			consts.BucketNameImmutableErrMessage,
+		))
+	case oldGeneratedBucketName == "" && generatedBucketName != "":
+		ctx, cancel := context.WithTimeout(context.Background(), ValidationTimeout)
+		defer cancel()
+		allErrs = validateBucketName(ctx, sb, allErrs)
+	}
+
+	// Bucket quota, subuser binding and public access Validators: the quota must not exceed the quota of the
+	// s3UserClaim, the bound subusers must be the subusers of the s3UserClaim and the public access must be allowed
+	// by the class of the s3UserClaim.
+	quotaChanged := sb.Spec.Quota != nil && !reflect.DeepEqual(sb.Spec.Quota, oldS3Bucket.Spec.Quota)
+	bindingsChanged := !reflect.DeepEqual(sb.Spec.S3SubuserBinding, oldS3Bucket.Spec.S3SubuserBinding)
+	publicAccessChanged := !reflect.DeepEqual(sb.Spec.PublicAccess, oldS3Bucket.Spec.PublicAccess)
+	statementsChanged := !reflect.DeepEqual(sb.Spec.AdditionalPolicyStatements, oldS3Bucket.Spec.AdditionalPolicyStatements)
+	if quotaChanged || bindingsChanged || publicAccessChanged || statementsChanged {
+		ctx, cancel := context.WithTimeout(context.Background(), ValidationTimeout)
+		defer cancel()
+
+		s3UserClaim := &S3UserClaim{}
+		err := runtimeClient.Get(ctx, types.NamespacedName{Name: sb.Spec.S3UserRef, Namespace: sb.Namespace}, s3UserClaim)
+		if err != nil {
+			allErrs = append(allErrs, field.InternalError(field.NewPath("spec").Child("s3UserRef"),
+				fmt.Errorf("failed to get s3UserClaim, %w", err)))
+		} else {
+			if quotaChanged {
+				allErrs = validateBucketQuota(ctx, sb, s3UserClaim, allErrs)
+			}
+			if bindingsChanged {
+				allErrs = validateSubuserBindings(sb, s3UserClaim, allErrs)
+			}
+			if publicAccessChanged {
+				allErrs = validatePublicAccess(ctx, sb, s3UserClaim, allErrs)
+			}
+			if statementsChanged {
+				allErrs = validatePublicPolicyStatements(ctx, sb, s3UserClaim, allErrs)
+			}
+		}
+	}
+
+	allErrs = validateLifecycle(sb.Spec.Lifecycle, allErrs)
+	allErrs = validateCORS(sb.Spec.CORS, allErrs)
+	allErrs = validatePolicyStatements(sb.Spec.AdditionalPolicyStatements, allErrs)
 
 	if len(allErrs) == 0 {
 		return nil
@@ -100,3 +194,326 @@ func (sb *S3Bucket) ValidateDelete() error {
 
 	return nil
 }
+
+func validateBucketQuota(ctx context.Context, sb *S3Bucket, s3UserClaim *S3UserClaim,
+	allErrs field.ErrorList) field.ErrorList {
+	if sb.Spec.Quota == nil {
+		return allErrs
+	}
+	quotaFieldPath := field.NewPath("spec").Child("quota")
+
+	userQuota, err := GetS3UserClaimQuota(ctx, runtimeClient, s3UserClaim)
+	if err != nil {
+		return append(allErrs, field.InternalError(quotaFieldPath, fmt.Errorf("failed to get s3UserClaim quota, %w", err)))
+	}
+	if !userQuota.MaxSize.IsZero() && sb.Spec.Quota.MaxSize.Cmp(userQuota.MaxSize) > 0 {
+		allErrs = append(allErrs, field.Forbidden(quotaFieldPath.Child("maxSize"), consts.BucketQuotaExceededErrMessage))
+	}
+	if !userQuota.MaxObjects.IsZero() && sb.Spec.Quota.MaxObjects.Cmp(userQuota.MaxObjects) > 0 {
+		allErrs = append(allErrs, field.Forbidden(quotaFieldPath.Child("maxObjects"), consts.BucketQuotaExceededErrMessage))
+	}
+	return allErrs
+}
+
+// validatePublicAccess checks that the prefixes are set only in the read-prefixes mode and that the class of the
+// s3UserClaim allows the public access for the namespace
+func validatePublicAccess(ctx context.Context, sb *S3Bucket, s3UserClaim *S3UserClaim,
+	allErrs field.ErrorList) field.ErrorList {
+	publicAccess := sb.Spec.PublicAccess
+	if publicAccess == nil {
+		return allErrs
+	}
+
+	publicAccessFieldPath := field.NewPath("spec").Child("publicAccess")
+	prefixesFieldPath := publicAccessFieldPath.Child("prefixes")
+	if (publicAccess.Mode == consts.PublicAccessModeReadPrefixes) != (len(publicAccess.Prefixes) > 0) {
+		allErrs = append(allErrs, field.Invalid(prefixesFieldPath, publicAccess.Prefixes,
+			consts.PublicAccessPrefixesErrMessage))
+	}
+	allErrs = validatePrefixes(publicAccess.Prefixes, prefixesFieldPath, allErrs)
+
+	if !publicAccess.IsEnabled() {
+		return allErrs
+	}
+	policy, err := GetPublicAccessPolicy(ctx, runtimeClient, s3UserClaim)
+	if err != nil {
+		return append(allErrs, field.InternalError(publicAccessFieldPath, err))
+	}
+	if !policy.Allows(sb.Namespace) {
+		allErrs = append(allErrs, field.Forbidden(publicAccessFieldPath.Child("mode"),
+			consts.PublicAccessNotAllowedErrMessage))
+	}
+	return allErrs
+}
+
+// validateSubuserBindings rejects the bindings of the subusers which aren't defined in the s3UserClaim, the duplicate
+// bindings and the invalid prefixes
+func validateSubuserBindings(sb *S3Bucket, s3UserClaim *S3UserClaim, allErrs field.ErrorList) field.ErrorList {
+	bindingsFieldPath := field.NewPath("spec").Child("s3SubuserBinding")
+
+	subusers := map[string]bool{}
+	for _, subuser := range s3UserClaim.Spec.Subusers {
+		subusers[string(subuser)] = true
+	}
+	boundSubusers := map[string]bool{}
+	for i, binding := range sb.Spec.S3SubuserBinding {
+		nameFieldPath := bindingsFieldPath.Index(i).Child("name")
+		if boundSubusers[binding.Name] {
+			allErrs = append(allErrs, field.Duplicate(nameFieldPath, binding.Name))
+		} else if !subusers[binding.Name] {
+			allErrs = append(allErrs, field.Invalid(nameFieldPath, binding.Name, consts.SubuserNotFoundErrMessage))
+		}
+		boundSubusers[binding.Name] = true
+		allErrs = validatePrefixes(binding.Prefixes, bindingsFieldPath.Index(i).Child("prefixes"), allErrs)
+	}
+	return allErrs
+}
+
+// validatePrefixes rejects the object key prefixes which are empty, begin with a slash or contain wildcards
+func validatePrefixes(prefixes []string, prefixesFieldPath *field.Path, allErrs field.ErrorList) field.ErrorList {
+	for i, prefix := range prefixes {
+		if prefix == "" || strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, "*?") {
+			allErrs = append(allErrs, field.Invalid(prefixesFieldPath.Index(i), prefix, consts.PrefixErrMessage))
+		}
+	}
+	return allErrs
+}
+
+// validatePublicPolicyStatements checks that the class of the s3UserClaim allows the public access for the namespace
+// if an additional policy statement allows anonymous users
+func validatePublicPolicyStatements(ctx context.Context, sb *S3Bucket, s3UserClaim *S3UserClaim,
+	allErrs field.ErrorList) field.ErrorList {
+	publicStatements := sb.PublicPolicyStatements()
+	if len(publicStatements) == 0 {
+		return allErrs
+	}
+	statementsFieldPath := field.NewPath("spec").Child("additionalPolicyStatements")
+	policy, err := GetPublicAccessPolicy(ctx, runtimeClient, s3UserClaim)
+	if err != nil {
+		return append(allErrs, field.InternalError(statementsFieldPath, err))
+	}
+	if policy.Allows(sb.Namespace) {
+		return allErrs
+	}
+	for _, i := range publicStatements {
+		allErrs = append(allErrs, field.Forbidden(statementsFieldPath.Index(i), consts.PublicAccessNotAllowedErrMessage))
+	}
+	return allErrs
+}
+
+// validatePolicyStatements checks that the statements are IAM policy statements with the elements which a bucket
+// policy supports
+func validatePolicyStatements(statements []runtime.RawExtension, allErrs field.ErrorList) field.ErrorList {
+	statementsFieldPath := field.NewPath("spec").Child("additionalPolicyStatements")
+	for i, rawStatement := range statements {
+		statement := map[string]interface{}{}
+		if err := json.Unmarshal(rawStatement.Raw, &statement); err != nil {
+			allErrs = append(allErrs, field.Invalid(statementsFieldPath.Index(i), string(rawStatement.Raw),
+				fmt.Sprintf("%s: %s", consts.PolicyStatementErrMessage, err)))
+			continue
+		}
+		if err := validatePolicyStatement(statement); err != "" {
+			allErrs = append(allErrs, field.Invalid(statementsFieldPath.Index(i), string(rawStatement.Raw),
+				fmt.Sprintf("%s: %s", consts.PolicyStatementErrMessage, err)))
+		}
+	}
+	return allErrs
+}
+
+func validatePolicyStatement(statement map[string]interface{}) string {
+	for element := range statement {
+		if !policyStatementElements[element] {
+			return fmt.Sprintf("unknown element %s", element)
+		}
+	}
+	if effect := statement["Effect"]; effect != "Allow" && effect != "Deny" {
+		return "Effect must be Allow or Deny"
+	}
+	if (statement["Principal"] == nil) == (statement["NotPrincipal"] == nil) {
+		return "exactly one of Principal and NotPrincipal must be set"
+	}
+	if (statement["Resource"] == nil) == (statement["NotResource"] == nil) {
+		return "exactly one of Resource and NotResource must be set"
+	}
+	if (statement["Action"] == nil) == (statement["NotAction"] == nil) {
+		return "exactly one of Action and NotAction must be set"
+	}
+	for _, element := range []string{"Action", "NotAction"} {
+		actions, err := stringOrStrings(statement[element])
+		if err != "" {
+			return fmt.Sprintf("%s %s", element, err)
+		}
+		for _, action := range actions {
+			if !strings.HasPrefix(action, "s3:") {
+				return fmt.Sprintf("%s %s isn't an s3 action", element, action)
+			}
+		}
+	}
+	for _, element := range []string{"Resource", "NotResource"} {
+		if _, err := stringOrStrings(statement[element]); err != "" {
+			return fmt.Sprintf("%s %s", element, err)
+		}
+	}
+	if condition, ok := statement["Condition"]; ok {
+		if _, ok := condition.(map[string]interface{}); !ok {
+			return "Condition must be an object"
+		}
+	}
+	return ""
+}
+
+// stringOrStrings returns the value of a policy element which is either a string or a list of strings
+func stringOrStrings(value interface{}) ([]string, string) {
+	switch value := value.(type) {
+	case nil:
+		return nil, ""
+	case string:
+		return []string{value}, ""
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			str, ok := item.(string)
+			if !ok {
+				return nil, "must be a string or a list of strings"
+			}
+			values = append(values, str)
+		}
+		return values, ""
+	}
+	return nil, "must be a string or a list of strings"
+}
+
+func validateExistingBucket(existingBucket string, allErrs field.ErrorList) field.ErrorList {
+	if existingBucket == "" {
+		return allErrs
+	}
+	tenant, bucket, found := strings.Cut(existingBucket, "/")
+	if !found {
+		bucket = tenant
+	}
+	if bucket == "" || strings.Contains(bucket, "/") || (found && tenant == "") {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("existingBucket"), existingBucket,
+			consts.ExistingBucketFormatErrMessage))
+	}
+	return allErrs
+}
+
+// validateBucketName validates the name of the bucket in Ceph, whether it's set, generated or the name of the
+// S3Bucket. An imported bucket keeps its own name, so it's not validated. The name to be generated is only validated
+// against the naming rules, while the generated one is also checked for conflicts.
+func validateBucketName(ctx context.Context, sb *S3Bucket, allErrs field.ErrorList) field.ErrorList {
+	specFieldPath := field.NewPath("spec")
+	nameSources := 0
+	for _, isSet := range []bool{sb.Spec.BucketName != "", sb.Spec.GenerateBucketName != nil,
+		sb.Spec.ExistingBucket != ""} {
+		if isSet {
+			nameSources++
+		}
+	}
+	if nameSources > 1 {
+		return append(allErrs, field.Forbidden(specFieldPath, consts.BucketNameConflictErrMessage))
+	}
+
+	var bucketName string
+	var bucketNameFieldPath *field.Path
+	switch {
+	case sb.Spec.ExistingBucket != "":
+		return allErrs
+	case sb.Spec.GenerateBucketName != nil && sb.Annotations[consts.AnnotationGeneratedBucketName] != "":
+		bucketName = sb.Annotations[consts.AnnotationGeneratedBucketName]
+		bucketNameFieldPath = field.NewPath("metadata").Child("annotations").Key(consts.AnnotationGeneratedBucketName)
+	case sb.Spec.GenerateBucketName != nil:
+		// The random part is made of lowercase letters and digits, so any of them stands for it
+		bucketName = sb.generateBucketName(strings.Repeat("a", BucketNameRandomLength))
+		bucketNameFieldPath = specFieldPath.Child("generateBucketName")
+	case sb.Spec.BucketName != "":
+		bucketName = sb.Spec.BucketName
+		bucketNameFieldPath = specFieldPath.Child("bucketName")
+	default:
+		bucketName = sb.Name
+		bucketNameFieldPath = field.NewPath("metadata").Child("name")
+	}
+	if !isValidBucketName(bucketName) {
+		return append(allErrs, field.Invalid(bucketNameFieldPath, bucketName, consts.BucketNameFormatErrMessage))
+	}
+	if sb.Spec.GenerateBucketName != nil && sb.Annotations[consts.AnnotationGeneratedBucketName] == "" {
+		return allErrs
+	}
+
+	s3BucketList := &S3BucketList{}
+	if err := runtimeClient.List(ctx, s3BucketList, client.InNamespace(sb.Namespace)); err != nil {
+		return append(allErrs, field.InternalError(bucketNameFieldPath, fmt.Errorf("failed to list s3Buckets, %w", err)))
+	}
+	for _, s3Bucket := range s3BucketList.Items {
+		if s3Bucket.Name != sb.Name && s3Bucket.GetBucketName() == bucketName {
+			allErrs = append(allErrs, field.Forbidden(bucketNameFieldPath, consts.BucketNameInUseErrMessage))
+			break
+		}
+	}
+	return allErrs
+}
+
+// isValidBucketName checks the name against the S3 bucket naming rules
+func isValidBucketName(name string) bool {
+	return bucketNameRegex.MatchString(name) && !strings.Contains(name, "..") && net.ParseIP(name) == nil &&
+		!strings.HasPrefix(name, "xn--") && !strings.HasSuffix(name, "-s3alias")
+}
+
+func validateLifecycle(lifecycle *BucketLifecycle, allErrs field.ErrorList) field.ErrorList {
+	if lifecycle == nil {
+		return allErrs
+	}
+	rulesFieldPath := field.NewPath("spec").Child("lifecycle").Child("rules")
+
+	ruleIDs := map[string]bool{}
+	for i, rule := range lifecycle.Rules {
+		ruleFieldPath := rulesFieldPath.Index(i)
+		if ruleIDs[rule.ID] {
+			allErrs = append(allErrs, field.Invalid(ruleFieldPath.Child("id"), rule.ID,
+				consts.LifecycleRuleDuplicateIDErrMessage))
+		}
+		ruleIDs[rule.ID] = true
+
+		if rule.ExpirationDays == nil && rule.NoncurrentVersionExpirationDays == nil &&
+			rule.AbortIncompleteMultipartUploadDays == nil && len(rule.Transitions) == 0 {
+			allErrs = append(allErrs, field.Invalid(ruleFieldPath, rule.ID, consts.LifecycleRuleNoActionErrMessage))
+		}
+
+		if rule.Filter != nil {
+			for key := range rule.Filter.Tags {
+				if key == "" {
+					allErrs = append(allErrs, field.Invalid(ruleFieldPath.Child("filter").Child("tags"), key,
+						consts.LifecycleTagKeyEmptyErrMessage))
+				}
+			}
+		}
+
+		var previousDays int64
+		for j, transition := range rule.Transitions {
+			if transition.Days <= previousDays ||
+				(rule.ExpirationDays != nil && transition.Days >= *rule.ExpirationDays) {
+				allErrs = append(allErrs, field.Invalid(ruleFieldPath.Child("transitions").Index(j).Child("days"),
+					transition.Days, consts.LifecycleTransitionOrderErrMessage))
+			}
+			previousDays = transition.Days
+		}
+	}
+	return allErrs
+}
+
+func validateCORS(cors *BucketCORS, allErrs field.ErrorList) field.ErrorList {
+	if cors == nil {
+		return allErrs
+	}
+	rulesFieldPath := field.NewPath("spec").Child("cors").Child("rules")
+
+	for i, rule := range cors.Rules {
+		for j, origin := range rule.AllowedOrigins {
+			if origin == "" || strings.Count(origin, "*") > 1 {
+				allErrs = append(allErrs, field.Invalid(rulesFieldPath.Index(i).Child("allowedOrigins").Index(j),
+					origin, consts.CORSOriginErrMessage))
+			}
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,443 @@
+package v1alpha1
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	openshiftquota "github.com/openshift/api/quota/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+var _ = Describe("S3Bucket webhook", Ordered, ContinueOnFailure, func() {
+	const (
+		teamName        = "s3bucket-test-team"
+		namespace       = "s3bucket-webhook-test"
+		s3UserClaimName = "test-s3userclaim"
+		s3BucketName    = "test-s3bucket"
+	)
+
+	var ctx = context.Background()
+
+	BeforeAll(func() {
+		Expect(k8sClient.Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   namespace,
+				Labels: map[string]string{consts.LabelTeam: teamName},
+			},
+		})).To(Succeed())
+
+		Expect(k8sClient.Create(ctx, &openshiftquota.ClusterResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: teamName,
+			},
+			Spec: openshiftquota.ClusterResourceQuotaSpec{
+				Selector: openshiftquota.ClusterResourceQuotaSelector{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{consts.LabelTeam: teamName},
+					},
+				},
+				Quota: v1.ResourceQuotaSpec{
+					Hard: v1.ResourceList{
+						consts.ResourceNameS3MaxSize:    resource.MustParse("5k"),
+						consts.ResourceNameS3MaxObjects: resource.MustParse("5k"),
+						consts.ResourceNameS3MaxBuckets: resource.MustParse("5k"),
+					},
+				},
+			},
+		})).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Create(ctx, getS3UserClaim(s3UserClaimName, namespace, &UserQuota{
+				MaxSize:    resource.MustParse("1k"),
+				MaxObjects: resource.MustParse("1k"),
+				MaxBuckets: 5,
+			}))).To(Succeed())
+		}).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		Eventually(func(g Gomega) {
+			s3BucketList := &S3BucketList{}
+			g.Expect(k8sClient.List(ctx, s3BucketList)).To(Succeed())
+
+			for _, s3Bucket := range s3BucketList.Items {
+				g.Expect(k8sClient.Delete(ctx, &s3Bucket)).To(Succeed())
+			}
+		}).WithTimeout(3 * time.Second).Should(Succeed())
+	})
+
+	AfterAll(func() {
+		// Other tests count the s3UserClaims of the whole cluster
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Delete(ctx, getS3UserClaim(s3UserClaimName, namespace, nil))).To(Succeed())
+		}).WithTimeout(3 * time.Second).Should(Succeed())
+	})
+
+	Context("When creating S3Bucket", func() {
+		It("Should deny creating if the bucket quota exceeds the quota of the s3UserClaim", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.Quota = &BucketQuota{
+				MaxSize:    resource.MustParse("2k"),
+				MaxObjects: resource.MustParse("1k"),
+			}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.BucketQuotaExceededErrMessage))
+		})
+
+		It("Should allow creating if the bucket quota doesn't exceed the quota of the s3UserClaim", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.Quota = &BucketQuota{
+				MaxSize:    resource.MustParse("1k"),
+				MaxObjects: resource.MustParse("500"),
+			}
+
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+		})
+
+		It("Should deny creating if the lifecycle rules are invalid", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.Lifecycle = &BucketLifecycle{
+				Rules: []LifecycleRule{
+					{ID: "expire", ExpirationDays: pointer.Int64(30)},
+					{ID: "expire", Transitions: []LifecycleTransition{
+						{Days: 60, StorageClass: "COLD"},
+						{Days: 30, StorageClass: "ARCHIVE"},
+					}},
+					{ID: "noop"},
+				},
+			}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.LifecycleRuleDuplicateIDErrMessage))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.LifecycleTransitionOrderErrMessage))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.LifecycleRuleNoActionErrMessage))
+		})
+
+		It("Should allow creating if the lifecycle rules are valid", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.Lifecycle = &BucketLifecycle{
+				Rules: []LifecycleRule{{
+					ID:             "expire-logs",
+					Filter:         &LifecycleFilter{Prefix: "logs/", Tags: map[string]string{"retention": "short"}},
+					ExpirationDays: pointer.Int64(30),
+					Transitions:    []LifecycleTransition{{Days: 7, StorageClass: "COLD"}},
+				}},
+			}
+
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+		})
+
+		It("Should deny creating if a CORS origin has more than one wildcard", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.CORS = &BucketCORS{
+				Rules: []CORSRule{{
+					AllowedOrigins: []string{"https://*.*.example.com"},
+					AllowedMethods: []CORSMethod{"GET"},
+				}},
+			}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.CORSOriginErrMessage))
+		})
+
+		It("Should deny creating if the existing bucket isn't in the form of [tenant/]bucket", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.ExistingBucket = "tenant/"
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.ExistingBucketFormatErrMessage))
+		})
+
+		It("Should deny creating if a bound subuser isn't defined in the s3UserClaim or is bound twice", func() {
+			s3UserClaim := &S3UserClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: s3UserClaimName},
+				s3UserClaim)).To(Succeed())
+			s3UserClaim.Spec.Subusers = []Subuser{"subuser1"}
+			Expect(k8sClient.Update(ctx, s3UserClaim)).To(Succeed())
+
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.S3SubuserBinding = []SubuserBinding{
+				{Name: "subuser1", Access: consts.BucketAccessRead},
+				{Name: "subuser1", Access: consts.BucketAccessWrite},
+				{Name: "subuser2", Access: consts.BucketAccessRead},
+			}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.SubuserNotFoundErrMessage))
+			Expect(apiStatus.Status().Message).To(ContainSubstring("Duplicate value"))
+		})
+
+		It("Should deny creating if a prefix of a subuser binding contains wildcards", func() {
+			s3UserClaim := &S3UserClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: s3UserClaimName},
+				s3UserClaim)).To(Succeed())
+			s3UserClaim.Spec.Subusers = []Subuser{"subuser1"}
+			Expect(k8sClient.Update(ctx, s3UserClaim)).To(Succeed())
+
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.S3SubuserBinding = []SubuserBinding{
+				{Name: "subuser1", Access: consts.BucketAccessList, Prefixes: []string{"reports/", "logs/*"}},
+			}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.PrefixErrMessage))
+		})
+
+		It("Should deny removing a subuser from the s3UserClaim if it's bound in an s3Bucket", func() {
+			s3UserClaim := &S3UserClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: s3UserClaimName},
+				s3UserClaim)).To(Succeed())
+			s3UserClaim.Spec.Subusers = []Subuser{"subuser1"}
+			Expect(k8sClient.Update(ctx, s3UserClaim)).To(Succeed())
+
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.S3SubuserBinding = []SubuserBinding{{Name: "subuser1", Access: consts.BucketAccessRead}}
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+
+			s3UserClaim.Spec.Subusers = nil
+			err := k8sClient.Update(ctx, s3UserClaim)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.SubuserStillBoundErrMessage))
+		})
+
+		It("Should deny creating if an additional policy statement isn't a valid IAM statement", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.AdditionalPolicyStatements = []runtime.RawExtension{
+				{Raw: []byte(`{"Effect":"Deny","Principal":"*","Action":"s3:*","Resource":"arn:aws:s3:::test-s3bucket/*",` +
+					`"Condition":{"NotIpAddress":{"aws:SourceIp":["10.0.0.0/8"]}}}`)},
+				{Raw: []byte(`{"Effect":"Permit","Principal":"*","Action":"s3:GetObject"}`)},
+			}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.PolicyStatementErrMessage))
+			Expect(apiStatus.Status().Message).To(ContainSubstring("additionalPolicyStatements[1]"))
+		})
+
+		It("Should deny an additional policy statement allowing anonymous users unless the namespace is allowed", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.AdditionalPolicyStatements = []runtime.RawExtension{
+				{Raw: []byte(`{"Effect":"Deny","Principal":"*","Action":"s3:*","Resource":"arn:aws:s3:::test-s3bucket/*",` +
+					`"Condition":{"Bool":{"aws:SecureTransport":"false"}}}`)},
+				{Raw: []byte(`{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":"s3:GetObject",` +
+					`"Resource":"arn:aws:s3:::test-s3bucket/*"}`)},
+			}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.PublicAccessNotAllowedErrMessage))
+			Expect(apiStatus.Status().Message).To(ContainSubstring("additionalPolicyStatements[1]"))
+			Expect(apiStatus.Status().Message).NotTo(ContainSubstring("additionalPolicyStatements[0]"))
+
+			DefaultPublicAccessPolicy.AllowedNamespaces = []string{namespace}
+			defer func() { DefaultPublicAccessPolicy.AllowedNamespaces = nil }()
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+		})
+
+		It("Should deny creating a public s3Bucket if the namespace isn't allowed by the s3UserClass", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.PublicAccess = &BucketPublicAccess{Mode: consts.PublicAccessModeReadObjects}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.PublicAccessNotAllowedErrMessage))
+		})
+
+		It("Should deny creating a public s3Bucket if the prefixes don't match the mode", func() {
+			DefaultPublicAccessPolicy.AllowedNamespaces = []string{namespace}
+			defer func() { DefaultPublicAccessPolicy.AllowedNamespaces = nil }()
+
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.PublicAccess = &BucketPublicAccess{Mode: consts.PublicAccessModeReadPrefixes}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.PublicAccessPrefixesErrMessage))
+		})
+
+		It("Should allow creating a public s3Bucket if the namespace is allowed by the s3UserClass", func() {
+			DefaultPublicAccessPolicy.AllowedNamespaces = []string{namespace}
+			defer func() { DefaultPublicAccessPolicy.AllowedNamespaces = nil }()
+
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.PublicAccess = &BucketPublicAccess{
+				Mode:     consts.PublicAccessModeReadPrefixes,
+				Prefixes: []string{"assets/"},
+			}
+
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+		})
+
+		It("Should deny creating if the bucket name doesn't follow the S3 bucket naming rules", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.BucketName = "Invalid..Bucket"
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.BucketNameFormatErrMessage))
+		})
+
+		It("Should deny creating if both bucketName and generateBucketName are set", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.BucketName = "my-bucket"
+			s3Bucket.Spec.GenerateBucketName = &BucketNameGeneration{Prefix: "{namespace}-"}
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.BucketNameConflictErrMessage))
+		})
+
+		It("Should deny creating if the bucket name is used by another s3Bucket", func() {
+			Expect(k8sClient.Create(ctx, getS3Bucket(s3BucketName, namespace, s3UserClaimName))).To(Succeed())
+
+			s3Bucket := getS3Bucket("another-s3bucket", namespace, s3UserClaimName)
+			s3Bucket.Spec.BucketName = s3BucketName
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.BucketNameInUseErrMessage))
+		})
+
+		It("Should allow creating if the generated bucket name follows the S3 bucket naming rules", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.GenerateBucketName = &BucketNameGeneration{Prefix: "{namespace}-", Suffix: "-data"}
+
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+		})
+
+		It("Should deny creating if the versioning is neither Enabled nor Suspended", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.Versioning = "Disabled"
+
+			err := k8sClient.Create(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring("spec.versioning"))
+		})
+
+		It("Should allow creating if the versioning is Enabled or Suspended", func() {
+			for _, versioning := range []string{"Enabled", "Suspended"} {
+				s3Bucket := getS3Bucket(s3BucketName+"-"+strings.ToLower(versioning), namespace, s3UserClaimName)
+				s3Bucket.Spec.Versioning = versioning
+
+				Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+			}
+		})
+	})
+
+	Context("When updating S3Bucket", func() {
+		It("Should deny updating if the bucket quota exceeds the quota of the s3UserClaim", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+
+			s3Bucket.Spec.Quota = &BucketQuota{MaxObjects: resource.MustParse("2k")}
+			err := k8sClient.Update(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.BucketQuotaExceededErrMessage))
+		})
+
+		It("Should deny updating if the existing bucket is changed", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+
+			s3Bucket.Spec.ExistingBucket = "legacy-bucket"
+			err := k8sClient.Update(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.ExistingBucketImmutableErrMessage))
+		})
+
+		It("Should deny updating if the bucket name is changed", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.BucketName = "my-bucket"
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+
+			s3Bucket.Spec.BucketName = "my-other-bucket"
+			err := k8sClient.Update(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.BucketNameImmutableErrMessage))
+		})
+
+		It("Should deny updating if the generated bucket name is changed", func() {
+			s3Bucket := getS3Bucket(s3BucketName, namespace, s3UserClaimName)
+			s3Bucket.Spec.GenerateBucketName = &BucketNameGeneration{Prefix: "{namespace}-"}
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+
+			By("Expect the generated bucket name to be set once")
+			s3Bucket.Annotations = map[string]string{consts.AnnotationGeneratedBucketName: namespace + "-abcd1234"}
+			Expect(k8sClient.Update(ctx, s3Bucket)).To(Succeed())
+
+			s3Bucket.Annotations[consts.AnnotationGeneratedBucketName] = namespace + "-efgh5678"
+			err := k8sClient.Update(ctx, s3Bucket)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.BucketNameImmutableErrMessage))
+		})
+	})
+})
+
+func getS3Bucket(name, namespace, s3UserRef string) *S3Bucket {
+	return &S3Bucket{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: S3BucketSpec{
+			S3UserRef: s3UserRef,
+		},
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// S3BucketAccessSpec either grants access on an S3Bucket of the namespace to an S3UserClaim of another namespace, or
+// accepts such a grant in the namespace of the grantee. A grant takes effect only once it's accepted.
+type S3BucketAccessSpec struct {
+	// access granted on an S3Bucket of the namespace
+	// +kubebuilder:validation:Optional
+	Grant *BucketAccessGrant `json:"grant,omitempty"`
+
+	// grant which is accepted by the namespace of its grantee
+	// +kubebuilder:validation:Optional
+	Accept *BucketAccessReference `json:"accept,omitempty"`
+}
+
+// BucketAccessGrant grants access on an S3Bucket to an S3UserClaim, or one of its subusers, in another namespace
+type BucketAccessGrant struct {
+	// name of the S3Bucket in the namespace of the grant
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	S3BucketRef string `json:"s3BucketRef"`
+
+	// +kubebuilder:validation:Required
+	Grantee BucketAccessGrantee `json:"grantee"`
+
+	// access of the grantee which can be read, write, list (listing only) or writeonly (uploading only)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=read
+	// +kubebuilder:validation:Enum=read;write;list;writeonly
+	Access string `json:"access,omitempty"`
+
+	// object key prefixes which the access is limited to, the access covers the whole bucket if it's not set
+	// +kubebuilder:validation:Optional
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// BucketAccessGrantee is the S3UserClaim, or one of its subusers, which is granted access on a bucket
+type BucketAccessGrantee struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	S3UserClaim string `json:"s3UserClaim"`
+
+	// subuser of the S3UserClaim, the Ceph user of the claim is the grantee if it's not set
+	// +kubebuilder:validation:Optional
+	Subuser string `json:"subuser,omitempty"`
+}
+
+// BucketAccessReference references the S3BucketAccess of a grant
+type BucketAccessReference struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// S3BucketAccessStatus defines the observed state of S3BucketAccess
+type S3BucketAccessStatus struct {
+	// name of the bucket in Ceph which the access is granted on
+	// +kubebuilder:validation:Optional
+	Bucket string `json:"bucket,omitempty"`
+
+	// principal of the grantee in the bucket policy, which is set while the grant is accepted
+	// +kubebuilder:validation:Optional
+	Principal string `json:"principal,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="S3BUCKETREF",type=string,JSONPath=`.spec.grant.s3BucketRef`
+// +kubebuilder:printcolumn:name="GRANTEE NS",type=string,JSONPath=`.spec.grant.grantee.namespace`
+// +kubebuilder:printcolumn:name="ACCEPTED NS",type=string,JSONPath=`.spec.accept.namespace`
+// +kubebuilder:printcolumn:name="READY",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="PRINCIPAL",type=string,JSONPath=`.status.principal`,priority=1
+// +kubebuilder:resource:shortName=s3ba
+
+// S3 Bucket Access grants access on a bucket to another namespace, or accepts such a grant
+type S3BucketAccess struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   S3BucketAccessSpec   `json:"spec,omitempty"`
+	Status S3BucketAccessStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// S3BucketAccessList contains a list of S3BucketAccess
+type S3BucketAccessList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3BucketAccess `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&S3BucketAccess{}, &S3BucketAccessList{})
+}
+
+// Accepts reports whether the S3BucketAccess accepts the grant
+func (sba *S3BucketAccess) Accepts(grant *S3BucketAccess) bool {
+	return sba.Spec.Accept != nil && sba.Spec.Accept.Namespace == grant.Namespace &&
+		sba.Spec.Accept.Name == grant.Name && grant.Spec.Grant != nil &&
+		grant.Spec.Grant.Grantee.Namespace == sba.Namespace
+}
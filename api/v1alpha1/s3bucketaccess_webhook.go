@@ -0,0 +1,126 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// log is for logging in this package.
+var s3bucketaccesslog = logf.Log.WithName("s3bucketaccess-resource")
+
+func (sba *S3BucketAccess) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	runtimeClient = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(sba).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-s3-snappcloud-io-v1alpha1-s3bucketaccess,mutating=false,failurePolicy=fail,sideEffects=None,groups=s3.snappcloud.io,resources=s3bucketaccesses,verbs=create;update,versions=v1alpha1,name=vs3bucketaccess.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &S3BucketAccess{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (sba *S3BucketAccess) ValidateCreate() error {
+	s3bucketaccesslog.Info("validate create", "name", sba.Name)
+	allErrs := field.ErrorList{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ValidationTimeout)
+	defer cancel()
+
+	specFieldPath := field.NewPath("spec")
+	switch grant, accept := sba.Spec.Grant, sba.Spec.Accept; {
+	case (grant == nil) == (accept == nil):
+		allErrs = append(allErrs, field.Forbidden(specFieldPath, consts.BucketAccessModeErrMessage))
+	case grant != nil:
+		grantFieldPath := specFieldPath.Child("grant")
+		// S3BucketRef Validator: S3BucketRef must be previously defined as S3Bucket CR in the namespace.
+		err := runtimeClient.Get(ctx, types.NamespacedName{Namespace: sba.Namespace, Name: grant.S3BucketRef},
+			&S3Bucket{})
+		if err != nil {
+			allErrs = append(allErrs, field.Forbidden(grantFieldPath.Child("s3BucketRef"),
+				consts.S3BucketRefNotFoundErrMessage))
+		}
+		allErrs = validateGrant(sba, allErrs)
+	case accept.Namespace == sba.Namespace:
+		allErrs = append(allErrs, field.Invalid(specFieldPath.Child("accept").Child("namespace"), accept.Namespace,
+			consts.GranteeNamespaceErrMessage))
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(sba.GroupVersionKind().GroupKind(), sba.Name, allErrs)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (sba *S3BucketAccess) ValidateUpdate(old runtime.Object) error {
+	s3bucketaccesslog.Info("validate update", "name", sba.Name)
+	allErrs := field.ErrorList{}
+
+	oldS3BucketAccess, ok := old.(*S3BucketAccess)
+	if !ok {
+		s3bucketaccesslog.Info("invalid object passed as old s3BucketAccess", "type", old.GetObjectKind())
+		return fmt.Errorf(internalErrorMessage)
+	}
+
+	// The access and the prefixes of a grant can be changed, the rest is immutable
+	grant, oldGrant := sba.Spec.Grant, oldS3BucketAccess.Spec.Grant
+	if (grant == nil) != (oldGrant == nil) ||
+		(grant != nil && (grant.S3BucketRef != oldGrant.S3BucketRef || grant.Grantee != oldGrant.Grantee)) ||
+		!apiequality.Semantic.DeepEqual(sba.Spec.Accept, oldS3BucketAccess.Spec.Accept) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), consts.BucketAccessImmutableErrMessage))
+	} else if grant != nil {
+		allErrs = validateGrant(sba, allErrs)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(sba.GroupVersionKind().GroupKind(), sba.Name, allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (sba *S3BucketAccess) ValidateDelete() error {
+	s3bucketaccesslog.Info("validate delete", "name", sba.Name)
+
+	return nil
+}
+
+func validateGrant(sba *S3BucketAccess, allErrs field.ErrorList) field.ErrorList {
+	grant := sba.Spec.Grant
+	grantFieldPath := field.NewPath("spec").Child("grant")
+
+	// The subusers of the same claim are bound to the bucket by the s3SubuserBinding of the S3Bucket
+	if grant.Grantee.Namespace == sba.Namespace {
+		allErrs = append(allErrs, field.Invalid(grantFieldPath.Child("grantee").Child("namespace"),
+			grant.Grantee.Namespace, consts.GranteeNamespaceErrMessage))
+	}
+	return validatePrefixes(grant.Prefixes, grantFieldPath.Child("prefixes"), allErrs)
+}
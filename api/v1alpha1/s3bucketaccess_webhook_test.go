@@ -0,0 +1,73 @@
+package v1alpha1
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+var _ = Describe("S3BucketAccess webhook", Ordered, ContinueOnFailure, func() {
+	const (
+		namespace          = "s3bucketaccess-webhook-test"
+		s3BucketAccessName = "test-s3bucketaccess"
+	)
+
+	var ctx = context.Background()
+
+	BeforeAll(func() {
+		Expect(k8sClient.Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		})).To(Succeed())
+	})
+
+	Context("When creating S3BucketAccess", func() {
+		It("Should deny creating if neither grant nor accept is set", func() {
+			err := k8sClient.Create(ctx, &S3BucketAccess{
+				ObjectMeta: metav1.ObjectMeta{Name: s3BucketAccessName, Namespace: namespace},
+			})
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.BucketAccessModeErrMessage))
+		})
+
+		It("Should deny granting access on a missing s3Bucket to the same namespace", func() {
+			err := k8sClient.Create(ctx, &S3BucketAccess{
+				ObjectMeta: metav1.ObjectMeta{Name: s3BucketAccessName, Namespace: namespace},
+				Spec: S3BucketAccessSpec{
+					Grant: &BucketAccessGrant{
+						S3BucketRef: "missing-s3bucket",
+						Grantee:     BucketAccessGrantee{Namespace: namespace, S3UserClaim: "test-s3userclaim"},
+						Access:      consts.BucketAccessRead,
+					},
+				},
+			})
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.S3BucketRefNotFoundErrMessage))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.GranteeNamespaceErrMessage))
+		})
+
+		It("Should deny accepting a grant of the same namespace", func() {
+			err := k8sClient.Create(ctx, &S3BucketAccess{
+				ObjectMeta: metav1.ObjectMeta{Name: s3BucketAccessName, Namespace: namespace},
+				Spec: S3BucketAccessSpec{
+					Accept: &BucketAccessReference{Namespace: namespace, Name: "test-grant"},
+				},
+			})
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.GranteeNamespaceErrMessage))
+		})
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// S3TeamQuotaSpec defines the quota of the S3UserClaims of a team
+type S3TeamQuotaSpec struct {
+	// selector of the namespaces of the team
+	// +kubebuilder:validation:Required
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// hard limits of the s3/size, s3/objects and s3/buckets resources summed over the S3UserClaims of the namespaces
+	// +kubebuilder:validation:Required
+	Hard v1.ResourceList `json:"hard"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// S3 Team Quota limits the S3UserClaims of the namespaces of a team when the s3TeamQuota team quota backend is used
+type S3TeamQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec S3TeamQuotaSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// S3TeamQuotaList contains a list of S3TeamQuota
+type S3TeamQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3TeamQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&S3TeamQuota{}, &S3TeamQuotaList{})
+}
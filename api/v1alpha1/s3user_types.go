@@ -31,10 +31,35 @@ type S3UserSpec struct {
 
 	// +kubebuilder:validation:Optional
 	ClaimRef *v1.ObjectReference `json:"claimRef,omitempty"`
+
+	// full ID of the Ceph user, including its tenant
+	// +kubebuilder:validation:Optional
+	CephUserID string `json:"cephUserID,omitempty"`
+
+	// deletion policy of the claim, which is kept here to clean up the Ceph user after the claim is gone
+	// +kubebuilder:validation:Optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
 }
 
 // S3UserStatus defines the observed state of S3User
 type S3UserStatus struct {
+	// time which the claim was deleted at while the Ceph user was retained
+	// +kubebuilder:validation:Optional
+	RetainedTime *metav1.Time `json:"retainedTime,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Usage *UserUsage `json:"usage,omitempty"`
+
+	// generation of the S3User which was last reconciled
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 //+kubebuilder:object:root=true
@@ -46,6 +71,12 @@ type S3UserStatus struct {
 // +kubebuilder:printcolumn:name="MAX OBJECTS",type=string,JSONPath=`.spec.quota.maxObjects`
 // +kubebuilder:printcolumn:name="MAX SIZE",type=string,JSONPath=`.spec.quota.maxSize`
 // +kubebuilder:printcolumn:name="MAX BUCKETS",type=string,JSONPath=`.spec.quota.maxBuckets`
+// +kubebuilder:printcolumn:name="USED SIZE",type=string,JSONPath=`.status.usage.size`
+// +kubebuilder:printcolumn:name="USED OBJECTS",type=string,JSONPath=`.status.usage.objects`
+// +kubebuilder:printcolumn:name="READY",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="CEPH USER",type=string,JSONPath=`.spec.cephUserID`,priority=1
+// +kubebuilder:printcolumn:name="DELETION POLICY",type=string,JSONPath=`.spec.deletionPolicy`,priority=1
+// +kubebuilder:printcolumn:name="RETAINED",type=date,JSONPath=`.status.retainedTime`,priority=1
 // +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // S3 User is created by the S3 User Claim instance. It's not applicable for the operator user.
@@ -18,10 +18,13 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
 )
 
 // S3UserClaimSpec defines the desired state of S3UserClaim
 type S3UserClaimSpec struct {
+	// name of the S3UserClass which serves the claim, defaults to the s3UserClass of the operator config
 	// +kubebuilder:validation:Optional
 	S3UserClass string `json:"s3UserClass,omitempty"`
 
@@ -31,12 +34,28 @@ type S3UserClaimSpec struct {
 	// +kubebuilder:validation:Required
 	AdminSecret string `json:"adminSecret"`
 
+	// quota of the user, defaults to the default quota of the s3UserClass
 	// +kubebuilder:validation:Optional
-	// +kubebuilder:default:={"maxSize":"5368709120", "maxObjects":"1000", "maxBuckets": 2}
 	Quota *UserQuota `json:"quota,omitempty"`
 
 	// +kubebuilder:validation:Optional
 	Subusers []Subuser `json:"subusers,omitempty"`
+
+	// rotation of the keys of the user and its subusers
+	// +kubebuilder:validation:Optional
+	KeyRotation *KeyRotation `json:"keyRotation,omitempty"`
+
+	// what happens to the Ceph user and its data when the claim is deleted. Delete removes the user and purges
+	// its data, Retain keeps them and records the user on the S3User object, Orphan keeps them and forgets the user.
+	// Defaults to Delete, or to Retain for the claims adopting an existing user.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// full ID of an existing Ceph user to adopt in the form of [tenant$]user. The user is not created but bound to
+	// the claim and its existing keys are imported into the secrets.
+	// +kubebuilder:validation:Optional
+	ExistingUser string `json:"existingUser,omitempty"`
 }
 
 // S3UserClaimStatus defines the observed state of S3UserClaim
@@ -49,15 +68,36 @@ type S3UserClaimStatus struct {
 
 	// +kubebuilder:validation:Optional
 	Subusers []Subuser `json:"subusers,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Usage *UserUsage `json:"usage,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	KeyRotation *KeyRotationStatus `json:"keyRotation,omitempty"`
+
+	// generation of the S3UserClaim which was last reconciled
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="S3USERCLASS",type=string,JSONPath=`.spec.s3UserClass`
 // +kubebuilder:printcolumn:name="S3USER",type=string,JSONPath=`.status.s3UserName`
+// +kubebuilder:printcolumn:name="READY",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="MAX OBJECTS",type=string,JSONPath=`.status.quota.maxObjects`
 // +kubebuilder:printcolumn:name="MAX SIZE",type=string,JSONPath=`.status.quota.maxSize`
 // +kubebuilder:printcolumn:name="MAX BUCKETS",type=string,JSONPath=`.status.quota.maxBuckets`
+// +kubebuilder:printcolumn:name="USED SIZE",type=string,JSONPath=`.status.usage.size`
+// +kubebuilder:printcolumn:name="USED OBJECTS",type=string,JSONPath=`.status.usage.objects`
+// +kubebuilder:printcolumn:name="DELETION POLICY",type=string,JSONPath=`.spec.deletionPolicy`,priority=1
 // +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:resource:shortName=s3u
 
@@ -86,3 +126,16 @@ func init() {
 func (suc *S3UserClaim) GetS3UserClass() string {
 	return suc.Spec.S3UserClass
 }
+
+// GetDeletionPolicy returns the deletion policy of the claim. The data of an adopted user is never purged unless the
+// claim asks for it.
+func (suc *S3UserClaim) GetDeletionPolicy() string {
+	switch {
+	case suc.Spec.DeletionPolicy != "":
+		return suc.Spec.DeletionPolicy
+	case suc.Spec.ExistingUser != "":
+		return consts.UserDeletionPolicyRetain
+	default:
+		return consts.UserDeletionPolicyDelete
+	}
+}
@@ -20,9 +20,10 @@ import (
 	"context"
 	goerrors "errors"
 	"fmt"
+	"strings"
 	"time"
 
-	openshiftquota "github.com/openshift/api/quota/v1"
+	admissionv1 "k8s.io/api/admission/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
 )
@@ -56,6 +58,12 @@ func (suc *S3UserClaim) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	// https://github.com/kubernetes-sigs/controller-runtime/blob/main/FAQ.md#q-my-cache-might-be-stale-if-i-read-from-a-cache-how-should-i-deal-with-that
 	uncachedReader = mgr.GetAPIReader()
 
+	// The validating webhook is registered before the builder so that the quota warnings are added to its responses,
+	// the builder skips the paths which are already registered
+	mgr.GetWebhookServer().Register(validateS3UserClaimPath, &webhook.Admission{
+		Handler: &quotaWarningHandler{validator: admission.ValidatingWebhookFor(suc).Handler},
+	})
+
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(suc).
 		Complete()
@@ -65,11 +73,52 @@ func (suc *S3UserClaim) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 var _ webhook.Validator = &S3UserClaim{}
 
+const validateS3UserClaimPath = "/validate-s3-snappcloud-io-v1alpha1-s3userclaim"
+
+// quotaWarningHandler validates the S3UserClaims and warns about the namespaces and the teams whose consumption
+// reached the warning threshold of their quota
+type quotaWarningHandler struct {
+	validator admission.Handler
+	decoder   *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &quotaWarningHandler{}
+
+func (h *quotaWarningHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	_, err := admission.InjectDecoderInto(d, h.validator)
+	return err
+}
+
+func (h *quotaWarningHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	resp := h.validator.Handle(ctx, req)
+	if !resp.Allowed || req.Operation == admissionv1.Delete {
+		return resp
+	}
+
+	suc := &S3UserClaim{}
+	if err := h.decoder.Decode(req, suc); err != nil {
+		s3userclaimlog.Error(err, "failed to decode s3UserClaim")
+		return resp
+	}
+	ctx, cancel := context.WithTimeout(ctx, ValidationTimeout)
+	defer cancel()
+	// Failing to check the thresholds doesn't affect the admission, the controller checks them again
+	warnings, err := CheckQuotaThresholds(ctx, runtimeClient, uncachedReader, suc)
+	if err != nil {
+		s3userclaimlog.Error(err, "failed to check quota thresholds", "name", suc.Name)
+		return resp
+	}
+	return resp.WithWarnings(warnings...)
+}
+
 func (suc *S3UserClaim) ValidateCreate() error {
 	s3userclaimlog.Info("validate create", "name", suc.Name)
 	allErrs := field.ErrorList{}
 
 	allErrs = validateQuota(suc, allErrs)
+	allErrs = validateKeyRotation(suc.Spec.KeyRotation, allErrs)
+	allErrs = validateExistingUser(suc, allErrs)
 
 	secretNames := []string{suc.Spec.AdminSecret, suc.Spec.ReadonlySecret}
 	allErrs = validateSecrets(secretNames, suc.Namespace, allErrs)
@@ -97,8 +146,16 @@ func (suc *S3UserClaim) ValidateUpdate(old runtime.Object) error {
 			field.Forbidden(field.NewPath("spec").Child("s3UserClass"), consts.S3UserClassImmutableErrMessage),
 		)
 	}
+	if suc.Spec.ExistingUser != oldS3UserClaim.Spec.ExistingUser {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(field.NewPath("spec").Child("existingUser"), consts.ExistingUserImmutableErrMessage),
+		)
+	}
 
 	allErrs = validateQuota(suc, allErrs)
+	allErrs = validateKeyRotation(suc.Spec.KeyRotation, allErrs)
+	allErrs = validateRemovedSubusers(suc, oldS3UserClaim, allErrs)
 
 	// validate against updated secret names
 	var secretNames []string
@@ -140,23 +197,95 @@ func (suc *S3UserClaim) ValidateDelete() error {
 	return nil
 }
 
+// validateRemovedSubusers rejects the removal of the subusers which are still bound in the S3Buckets of the claim
+func validateRemovedSubusers(suc, oldS3UserClaim *S3UserClaim, allErrs field.ErrorList) field.ErrorList {
+	subusers := map[Subuser]bool{}
+	for _, subuser := range suc.Spec.Subusers {
+		subusers[subuser] = true
+	}
+	removedSubusers := map[string]bool{}
+	for _, subuser := range oldS3UserClaim.Spec.Subusers {
+		if !subusers[subuser] {
+			removedSubusers[string(subuser)] = true
+		}
+	}
+	if len(removedSubusers) == 0 {
+		return allErrs
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ValidationTimeout)
+	defer cancel()
+
+	subusersFieldPath := field.NewPath("spec").Child("subusers")
+	s3BucketList := &S3BucketList{}
+	if err := runtimeClient.List(ctx, s3BucketList, client.InNamespace(suc.Namespace)); err != nil {
+		return append(allErrs, field.InternalError(subusersFieldPath, fmt.Errorf("failed to list s3Buckets, %w", err)))
+	}
+	for _, bucket := range s3BucketList.Items {
+		if bucket.Spec.S3UserRef != suc.Name {
+			continue
+		}
+		for _, binding := range bucket.Spec.S3SubuserBinding {
+			if removedSubusers[binding.Name] {
+				allErrs = append(allErrs, field.Forbidden(subusersFieldPath,
+					fmt.Sprintf("%s: subuser %s is bound in s3Bucket %s", consts.SubuserStillBoundErrMessage,
+						binding.Name, bucket.Name)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateExistingUser checks the format of the existing user, that the namespace of the claim can adopt it and that
+// no other claim of the class adopts it
+func validateExistingUser(suc *S3UserClaim, allErrs field.ErrorList) field.ErrorList {
+	existingUser := suc.Spec.ExistingUser
+	if existingUser == "" {
+		return allErrs
+	}
+	existingUserFieldPath := field.NewPath("spec").Child("existingUser")
+	tenant, userId, found := strings.Cut(existingUser, "$")
+	if userId == "" || strings.Contains(userId, "$") || (found && tenant == "") {
+		return append(allErrs, field.Invalid(existingUserFieldPath, existingUser, consts.ExistingUserFormatErrMessage))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ValidationTimeout)
+	defer cancel()
+	switch adoptable, err := CanAdoptExistingUser(ctx, runtimeClient, suc); {
+	case err != nil:
+		allErrs = append(allErrs, field.InternalError(existingUserFieldPath, err))
+	case !adoptable:
+		allErrs = append(allErrs, field.Forbidden(existingUserFieldPath, consts.ExistingUserTenantErrMessage))
+	}
+
+	switch adoptingClaim, err := FindAdoptingS3UserClaim(ctx, uncachedReader, suc); {
+	case err != nil:
+		allErrs = append(allErrs, field.InternalError(existingUserFieldPath, err))
+	case adoptingClaim != "":
+		allErrs = append(allErrs, field.Forbidden(existingUserFieldPath,
+			fmt.Sprintf("%s: %s", consts.ExistingUserAlreadyAdoptedErrMessage, adoptingClaim)))
+	}
+	return allErrs
+}
+
 func validateQuota(suc *S3UserClaim, allErrs field.ErrorList) field.ErrorList {
 	ctx, cancel := context.WithTimeout(context.Background(), ValidationTimeout)
 	defer cancel()
 
 	quotaFieldPath := field.NewPath("spec").Child("quota")
+	classQuotas := NewClassQuotas(uncachedReader)
 
 	// TODO(therealak12): refactor the code as there are similarities between two quota validator functions
 
-	switch err := validateAgainstNamespaceQuota(ctx, suc); {
-	case err == consts.ErrExceededNamespaceQuota:
+	switch err := validateAgainstNamespaceQuota(ctx, classQuotas, suc); {
+	case err == consts.ErrExceededNamespaceQuota, err == consts.ErrExceededNamespaceUsage:
 		allErrs = append(allErrs, field.Forbidden(quotaFieldPath, err.Error()))
 	case err != nil:
 		allErrs = append(allErrs, field.InternalError(quotaFieldPath, fmt.Errorf("failed to validate against cluster quota, %w", err)))
 	}
 
-	switch err := validateAgainstClusterQuota(ctx, suc); {
-	case err == consts.ErrExceededClusterQuota:
+	switch err := validateAgainstClusterQuota(ctx, classQuotas, suc); {
+	case err == consts.ErrExceededClusterQuota, err == consts.ErrExceededClusterUsage:
 		allErrs = append(allErrs, field.Forbidden(quotaFieldPath, err.Error()))
 	case goerrors.Is(err, consts.ErrClusterQuotaNotDefined):
 		allErrs = append(allErrs, field.Forbidden(quotaFieldPath, err.Error()))
@@ -166,11 +295,18 @@ func validateQuota(suc *S3UserClaim, allErrs field.ErrorList) field.ErrorList {
 	return allErrs
 }
 
-func validateAgainstNamespaceQuota(ctx context.Context, suc *S3UserClaim) error {
-	totalUsedQuota, err := CalculateNamespaceUsedQuota(ctx, uncachedReader, suc, suc.Namespace, true)
+func validateAgainstNamespaceQuota(ctx context.Context, classQuotas *ClassQuotas, suc *S3UserClaim) error {
+	totalUsedQuota, err := CalculateNamespaceUsedQuota(ctx, uncachedReader, classQuotas, suc, suc.Namespace, true)
 	if err != nil {
 		return fmt.Errorf("failed to calculate namespace used quota , %w", err)
 	}
+	var totalUsage *UserQuota
+	if QuotaEnforcement.IsOvercommit() {
+		totalUsage, err = CalculateNamespaceUsage(ctx, uncachedReader, suc, suc.Namespace, true)
+		if err != nil {
+			return fmt.Errorf("failed to calculate namespace usage, %w", err)
+		}
+	}
 	// List all quotas in the namespace and validate against them
 	resourceQuotaList := &v1.ResourceQuotaList{}
 	err = runtimeClient.List(ctx, resourceQuotaList, client.InNamespace(suc.Namespace))
@@ -178,65 +314,77 @@ func validateAgainstNamespaceQuota(ctx context.Context, suc *S3UserClaim) error
 		return fmt.Errorf("failed to list resource quotas, %w", err)
 	}
 	for _, quota := range resourceQuotaList.Items {
-		if maxObjects, ok := quota.Spec.Hard[consts.ResourceNameS3MaxObjects]; ok {
-			if totalUsedQuota.MaxObjects.Cmp(maxObjects) > 0 {
-				return consts.ErrExceededNamespaceQuota
-			}
-		}
-		if maxSize, ok := quota.Spec.Hard[consts.ResourceNameS3MaxSize]; ok {
-			if totalUsedQuota.MaxSize.Cmp(maxSize) > 0 {
-				return consts.ErrExceededNamespaceQuota
-			}
+		if QuotaEnforcement.exceedsRequested(quota.Spec.Hard, totalUsedQuota) {
+			return consts.ErrExceededNamespaceQuota
 		}
-		if maxBuckets, ok := quota.Spec.Hard[consts.ResourceNameS3MaxBuckets]; ok {
-			if totalUsedQuota.MaxBuckets > maxBuckets.Value() {
-				return consts.ErrExceededNamespaceQuota
-			}
+		if QuotaEnforcement.exceedsUsage(quota.Spec.Hard, totalUsage) {
+			return consts.ErrExceededNamespaceUsage
 		}
 	}
 
 	return nil
 }
 
-func validateAgainstClusterQuota(ctx context.Context, suc *S3UserClaim) error {
-	totalClusterUsedQuota, team, err := CalculateClusterUsedQuota(ctx, runtimeClient, suc, true)
+func validateAgainstClusterQuota(ctx context.Context, classQuotas *ClassQuotas, suc *S3UserClaim) error {
+	teamQuotas, err := GetTeamQuotas(ctx, runtimeClient, suc.Namespace)
 	if err != nil {
-		return fmt.Errorf("failed to calculate cluster resource used quota , %w", err)
+		// ErrClusterQuotaNotDefined is returned as is so that it's reported with the team
+		if goerrors.Is(err, consts.ErrClusterQuotaNotDefined) {
+			return err
+		}
+		return fmt.Errorf("failed to get team quotas, %w", err)
 	}
 
-	clusterQuota := &openshiftquota.ClusterResourceQuota{}
-	if err := runtimeClient.Get(ctx, types.NamespacedName{Name: team}, clusterQuota); err != nil {
-		if apierrors.IsNotFound(err) {
-			return fmt.Errorf("%w, team=%s", consts.ErrClusterQuotaNotDefined, team)
+	for i := range teamQuotas {
+		teamQuota := &teamQuotas[i]
+		// All the S3 resources must be limited by the quota of the team
+		for _, resourceName := range s3ResourceNames {
+			if _, ok := teamQuota.Hard[resourceName]; !ok {
+				return fmt.Errorf("%w, team=%s", consts.ErrClusterQuotaNotDefined, teamQuota.Name)
+			}
 		}
-		return fmt.Errorf("failed to get clusterQuota, %w", err)
-	}
-	// Validate against clusterResourceQuota
-	if maxObjects, ok := clusterQuota.Spec.Quota.Hard[consts.ResourceNameS3MaxObjects]; ok {
-		if totalClusterUsedQuota.MaxObjects.Cmp(maxObjects) > 0 {
-			return consts.ErrExceededClusterQuota
+
+		totalClusterUsedQuota, err := CalculateClusterUsedQuota(ctx, uncachedReader, classQuotas, teamQuota, suc, true)
+		if err != nil {
+			return fmt.Errorf("failed to calculate cluster resource used quota , %w", err)
 		}
-	} else {
-		return fmt.Errorf("%w, team=%s", consts.ErrClusterQuotaNotDefined, team)
-	}
-	if maxSize, ok := clusterQuota.Spec.Quota.Hard[consts.ResourceNameS3MaxSize]; ok {
-		if totalClusterUsedQuota.MaxSize.Cmp(maxSize) > 0 {
+		if QuotaEnforcement.exceedsRequested(teamQuota.Hard, totalClusterUsedQuota) {
 			return consts.ErrExceededClusterQuota
 		}
-	} else {
-		return fmt.Errorf("%w, team=%s", consts.ErrClusterQuotaNotDefined, team)
-	}
-	if maxBuckets, ok := clusterQuota.Spec.Quota.Hard[consts.ResourceNameS3MaxBuckets]; ok {
-		if totalClusterUsedQuota.MaxBuckets > maxBuckets.Value() {
-			return consts.ErrExceededClusterQuota
+		if QuotaEnforcement.IsOvercommit() {
+			totalClusterUsage, err := CalculateClusterUsage(ctx, uncachedReader, teamQuota, suc, true)
+			if err != nil {
+				return fmt.Errorf("failed to calculate cluster resource usage, %w", err)
+			}
+			if QuotaEnforcement.exceedsUsage(teamQuota.Hard, totalClusterUsage) {
+				return consts.ErrExceededClusterUsage
+			}
 		}
-	} else {
-		return fmt.Errorf("%w, team=%s", consts.ErrClusterQuotaNotDefined, team)
 	}
 
 	return nil
 }
 
+func validateKeyRotation(keyRotation *KeyRotation, allErrs field.ErrorList) field.ErrorList {
+	if keyRotation == nil {
+		return allErrs
+	}
+	keyRotationFieldPath := field.NewPath("spec").Child("keyRotation")
+
+	if keyRotation.Interval != nil && keyRotation.Interval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(keyRotationFieldPath.Child("interval"),
+			keyRotation.Interval.Duration.String(), consts.KeyRotationIntervalErrMessage))
+	}
+	if keyRotation.GracePeriod != nil {
+		gracePeriod := keyRotation.GracePeriod.Duration
+		if gracePeriod < 0 || (keyRotation.Interval != nil && gracePeriod >= keyRotation.Interval.Duration) {
+			allErrs = append(allErrs, field.Invalid(keyRotationFieldPath.Child("gracePeriod"),
+				gracePeriod.String(), consts.KeyRotationGracePeriodErrMessage))
+		}
+	}
+	return allErrs
+}
+
 func validateSecrets(secretNames []string, namespace string, allErrs field.ErrorList) field.ErrorList {
 	ctx, cancel := context.WithTimeout(context.Background(), ValidationTimeout)
 	defer cancel()
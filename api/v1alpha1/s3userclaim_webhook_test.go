@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"context"
 	goerrors "errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -13,6 +14,8 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
 )
@@ -118,6 +121,86 @@ var _ = Describe("", Ordered, ContinueOnFailure, func() {
 			}).Should(Succeed())
 		})
 
+		It("Should deny creating if the key rotation grace period isn't shorter than its interval", func() {
+			s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespaces[0], &UserQuota{
+				MaxSize:    resource.MustParse("1k"),
+				MaxObjects: resource.MustParse("1k"),
+			})
+			s3UserClaim.Spec.KeyRotation = &KeyRotation{
+				Interval:    &metav1.Duration{Duration: time.Hour},
+				GracePeriod: &metav1.Duration{Duration: 2 * time.Hour},
+			}
+
+			err := k8sClient.Create(ctx, s3UserClaim)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.KeyRotationGracePeriodErrMessage))
+		})
+
+		It("Should deny creating if the existing user isn't a valid Ceph user ID", func() {
+			s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespaces[0], &UserQuota{
+				MaxSize:    resource.MustParse("1k"),
+				MaxObjects: resource.MustParse("1k"),
+			})
+			s3UserClaim.Spec.ExistingUser = "tenant$"
+
+			err := k8sClient.Create(ctx, s3UserClaim)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.ExistingUserFormatErrMessage))
+		})
+
+		It("Should deny adopting a user of another tenant unless the s3UserClass allows the namespace", func() {
+			s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespaces[0], &UserQuota{
+				MaxSize:    resource.MustParse("1k"),
+				MaxObjects: resource.MustParse("1k"),
+			})
+			s3UserClaim.Spec.ExistingUser = "legacy$app"
+
+			err := k8sClient.Create(ctx, s3UserClaim)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.ExistingUserTenantErrMessage))
+
+			DefaultAdoptionPolicy.AllowedNamespaces = []string{targetNamespaces[0]}
+			defer func() { DefaultAdoptionPolicy.AllowedNamespaces = nil }()
+			Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+		})
+
+		It("Should allow adopting a user of the tenant of the namespace", func() {
+			s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespaces[0], &UserQuota{
+				MaxSize:    resource.MustParse("1k"),
+				MaxObjects: resource.MustParse("1k"),
+			})
+			s3UserClaim.Spec.ExistingUser = CephTenant(DefaultClusterName, targetNamespaces[0]) + "$app"
+
+			Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+		})
+
+		It("Should deny adopting a user which another s3UserClaim of the s3UserClass adopts", func() {
+			s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespaces[0], &UserQuota{
+				MaxSize:    resource.MustParse("1k"),
+				MaxObjects: resource.MustParse("1k"),
+			})
+			s3UserClaim.Spec.ExistingUser = CephTenant(DefaultClusterName, targetNamespaces[0]) + "$app"
+			Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+
+			secondS3UserClaim := getS3UserClaim("second-"+s3UserClaimName, targetNamespaces[0], &UserQuota{
+				MaxSize:    resource.MustParse("1k"),
+				MaxObjects: resource.MustParse("1k"),
+			})
+			secondS3UserClaim.Spec.ExistingUser = s3UserClaim.Spec.ExistingUser
+
+			err := k8sClient.Create(ctx, secondS3UserClaim)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.ExistingUserAlreadyAdoptedErrMessage))
+		})
+
 		It("Should deny creating if total requested max size exceeds cluster quota", func() {
 			Eventually(func(g Gomega) {
 				s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespaces[0], &UserQuota{
@@ -501,6 +584,185 @@ var _ = Describe("", Ordered, ContinueOnFailure, func() {
 		)
 	})
 
+	Context("When the quota is enforced in the overcommit mode", func() {
+		BeforeAll(func() {
+			QuotaEnforcement = QuotaEnforcementPolicy{Mode: consts.QuotaModeOvercommit, OvercommitRatio: 2}
+		})
+		AfterAll(func() {
+			QuotaEnforcement = QuotaEnforcementPolicy{Mode: consts.QuotaModeRequested, OvercommitRatio: 1}
+		})
+
+		It("Should allow creating if total requested quota exceeds the quota but not the overcommitted quota", func() {
+			Eventually(func(g Gomega) {
+				s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespaces[0], &UserQuota{
+					MaxSize:    resource.MustParse("4k"),
+					MaxObjects: resource.MustParse("4k"),
+				})
+				g.Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+			}).Should(Succeed())
+		})
+		It("Should deny creating if total requested quota exceeds the overcommitted quota", func() {
+			Eventually(func(g Gomega) {
+				s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespaces[0], &UserQuota{
+					MaxSize:    resource.MustParse("7k"),
+					MaxObjects: resource.MustParse("1k"),
+				})
+				err := k8sClient.Create(ctx, s3UserClaim)
+				var apiStatus apierrors.APIStatus
+				g.Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+				g.Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+				g.Expect(apiStatus.Status().Message).To(ContainSubstring(consts.ErrExceededNamespaceQuota.Error()))
+			}).Should(Succeed())
+		})
+		It("Should deny creating if total actual usage exceeds namespace quota", func() {
+			targetNamespace := targetNamespaces[0]
+			s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespace, &UserQuota{
+				MaxSize:    resource.MustParse("4k"),
+				MaxObjects: resource.MustParse("1k"),
+			})
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+			}).Should(Succeed())
+			s3UserClaim.Status.Usage = &UserUsage{
+				Size:    resource.MustParse("3500"),
+				Objects: resource.MustParse("10"),
+			}
+			Expect(k8sClient.Status().Update(ctx, s3UserClaim)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				s3UserClaim2 := getS3UserClaim(s3UserClaimName+"2", targetNamespace, &UserQuota{
+					MaxSize:    resource.MustParse("1k"),
+					MaxObjects: resource.MustParse("1k"),
+				})
+				err := k8sClient.Create(ctx, s3UserClaim2)
+				var apiStatus apierrors.APIStatus
+				g.Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+				g.Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+				g.Expect(apiStatus.Status().Message).To(ContainSubstring(consts.ErrExceededNamespaceUsage.Error()))
+				g.Expect(apiStatus.Status().Message).NotTo(ContainSubstring(consts.ErrExceededNamespaceQuota.Error()))
+			}).Should(Succeed())
+		})
+		It("Should warn if total actual usage reaches the warning threshold", func() {
+			QuotaEnforcement.WarningThresholdPercent = 50
+			defer func() { QuotaEnforcement.WarningThresholdPercent = 0 }()
+
+			targetNamespace := targetNamespaces[0]
+			s3UserClaim := getS3UserClaim(s3UserClaimName, targetNamespace, &UserQuota{
+				MaxSize:    resource.MustParse("4k"),
+				MaxObjects: resource.MustParse("1k"),
+			})
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+			}).Should(Succeed())
+			s3UserClaim.Status.Usage = &UserUsage{
+				Size:    resource.MustParse("2k"),
+				Objects: resource.MustParse("10"),
+			}
+			Expect(k8sClient.Status().Update(ctx, s3UserClaim)).To(Succeed())
+
+			warnings := &warningRecorder{}
+			warningConfig := rest.CopyConfig(cfg)
+			warningConfig.WarningHandler = warnings
+			warningClient, err := client.New(warningConfig, client.Options{Scheme: k8sClient.Scheme()})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func(g Gomega) {
+				s3UserClaim2 := getS3UserClaim(s3UserClaimName+"2", targetNamespace, &UserQuota{
+					MaxSize:    resource.MustParse("1k"),
+					MaxObjects: resource.MustParse("1k"),
+				})
+				g.Expect(warningClient.Create(ctx, s3UserClaim2)).To(Succeed())
+			}).Should(Succeed())
+			Expect(warnings.texts).To(ContainElement(
+				fmt.Sprintf("namespace %s consumes 66%% of its %s quota", targetNamespace, consts.ResourceNameS3MaxSize)))
+		})
+	})
+
+	Context("When the team quotas are S3TeamQuotas", func() {
+		const (
+			ns        = "s3teamquota-test"
+			teamLabel = "example.com/tenant"
+		)
+		BeforeAll(func() {
+			TeamQuotaBackend = consts.TeamQuotaBackendS3TeamQuota
+			Expect(k8sClient.Create(ctx, &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   ns,
+					Labels: map[string]string{teamLabel: "tenant"},
+				},
+			})).To(Succeed())
+			Expect(k8sClient.Create(ctx, &S3TeamQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+				Spec: S3TeamQuotaSpec{
+					NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{teamLabel: "tenant"}},
+					Hard: v1.ResourceList{
+						consts.ResourceNameS3MaxSize:    resource.MustParse("2k"),
+						consts.ResourceNameS3MaxObjects: resource.MustParse("2k"),
+						consts.ResourceNameS3MaxBuckets: resource.MustParse("2k"),
+					},
+				},
+			})).To(Succeed())
+		})
+		AfterAll(func() {
+			TeamQuotaBackend = consts.TeamQuotaBackendOpenShift
+		})
+
+		It("Should deny creating if total requested max size exceeds the S3TeamQuota", func() {
+			Eventually(func(g Gomega) {
+				s3UserClaim := getS3UserClaim(s3UserClaimName, ns, &UserQuota{
+					MaxSize:    resource.MustParse("1k"),
+					MaxObjects: resource.MustParse("1k"),
+				})
+				g.Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+			}).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				s3UserClaim2 := getS3UserClaim(s3UserClaimName+"2", ns, &UserQuota{
+					MaxSize:    resource.MustParse("2k"),
+					MaxObjects: resource.MustParse("1k"),
+				})
+				err := k8sClient.Create(ctx, s3UserClaim2)
+				var apiStatus apierrors.APIStatus
+				g.Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+				g.Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+				g.Expect(apiStatus.Status().Message).To(ContainSubstring(consts.ErrExceededClusterQuota.Error()))
+			}).Should(Succeed())
+		})
+		It("Should deny creating if no S3TeamQuota selects the namespace", func() {
+			Eventually(func(g Gomega) {
+				err := k8sClient.Create(ctx, getS3UserClaim(s3UserClaimName, targetNamespaces[0], &UserQuota{
+					MaxSize:    resource.MustParse("1k"),
+					MaxObjects: resource.MustParse("1k"),
+				}))
+				var apiStatus apierrors.APIStatus
+				g.Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+				g.Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+				g.Expect(apiStatus.Status().Message).To(ContainSubstring(consts.ErrClusterQuotaNotDefined.Error()))
+			}).Should(Succeed())
+		})
+	})
+
+	Context("When the team quotas are disabled", func() {
+		BeforeAll(func() {
+			TeamQuotaBackend = consts.TeamQuotaBackendDisabled
+		})
+		AfterAll(func() {
+			TeamQuotaBackend = consts.TeamQuotaBackendOpenShift
+		})
+
+		It("Should allow creating in a namespace without a team", func() {
+			const ns = "team-quota-disabled-test"
+			Expect(k8sClient.Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})).To(Succeed())
+			Eventually(func(g Gomega) {
+				s3UserClaim := getS3UserClaim(s3UserClaimName, ns, &UserQuota{
+					MaxSize:    resource.MustParse("10k"),
+					MaxObjects: resource.MustParse("10k"),
+				})
+				g.Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+			}).Should(Succeed())
+		})
+	})
+
 	Context("When creating S3UserClaim without ClusterResourceQuota", func() {
 		// Deny scenarios
 		It("Should deny", func() {
@@ -530,6 +792,15 @@ var _ = Describe("", Ordered, ContinueOnFailure, func() {
 	})
 })
 
+// warningRecorder records the warnings returned by the API server
+type warningRecorder struct {
+	texts []string
+}
+
+func (w *warningRecorder) HandleWarningHeader(_ int, _ string, text string) {
+	w.texts = append(w.texts, text)
+}
+
 func getS3UserClaim(name, namespace string, quota *UserQuota) *S3UserClaim {
 	return &S3UserClaim{
 		ObjectMeta: metav1.ObjectMeta{
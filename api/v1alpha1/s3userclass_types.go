@@ -0,0 +1,116 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// S3UserClassSpec defines the Ceph cluster which serves the S3UserClaims of the class
+type S3UserClassSpec struct {
+	// endpoint of the RGW admin and S3 API, e.g. http://rgw.example.com:8000
+	// +kubebuilder:validation:Required
+	Endpoint string `json:"endpoint"`
+
+	// reference to the secret holding the accessKey and secretKey of the RGW admin user, namespace must be set
+	// +kubebuilder:validation:Required
+	AdminSecretRef v1.SecretReference `json:"adminSecretRef"`
+
+	// name of the Ceph cluster which is used as the prefix of the Ceph tenants
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ClusterName string `json:"clusterName"`
+
+	// region of the S3 API
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=us-east-1
+	Region string `json:"region,omitempty"`
+
+	// TLS settings of the connection to RGW, the system CAs verify the certificate of RGW if it's not set
+	// +kubebuilder:validation:Optional
+	TLS *RgwTLS `json:"tls,omitempty"`
+
+	// timeout of the requests to RGW
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="15s"
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// max number of retries of the requests to RGW which fail on the network or with a server error
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=5
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+
+	// quota of the S3UserClaims of the class which don't specify a quota
+	// +kubebuilder:validation:Optional
+	DefaultQuota *UserQuota `json:"defaultQuota,omitempty"`
+
+	// namespaces whose S3Buckets can grant public access, no S3Bucket of the class can be public if it's not set
+	// +kubebuilder:validation:Optional
+	PublicAccess *PublicAccessPolicy `json:"publicAccess,omitempty"`
+
+	// namespaces whose S3UserClaims and S3Buckets can adopt the users and import the buckets of other tenants, only
+	// the users and the buckets of the tenant of the namespace can be adopted if it's not set
+	// +kubebuilder:validation:Optional
+	Adoption *AdoptionPolicy `json:"adoption,omitempty"`
+}
+
+// RgwTLS configures the verification of the certificate of RGW and the client certificate presented to it
+type RgwTLS struct {
+	// skips the verification of the certificate of RGW
+	// +kubebuilder:validation:Optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// reference to the secret holding the PEM bundle of the CAs which verify the certificate of RGW in its ca.crt key,
+	// namespace must be set
+	// +kubebuilder:validation:Optional
+	CASecretRef *v1.SecretReference `json:"caSecretRef,omitempty"`
+
+	// reference to the secret holding the client certificate and key in its tls.crt and tls.key keys, namespace must
+	// be set
+	// +kubebuilder:validation:Optional
+	ClientCertSecretRef *v1.SecretReference `json:"clientCertSecretRef,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName=s3class
+// +kubebuilder:printcolumn:name="ENDPOINT",type=string,JSONPath=`.spec.endpoint`
+// +kubebuilder:printcolumn:name="CLUSTER NAME",type=string,JSONPath=`.spec.clusterName`
+// +kubebuilder:printcolumn:name="REGION",type=string,JSONPath=`.spec.region`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// S3 User Class describes a Ceph cluster which S3UserClaims and S3Buckets can be provisioned on
+type S3UserClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec S3UserClassSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// S3UserClassList contains a list of S3UserClass
+type S3UserClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []S3UserClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&S3UserClass{}, &S3UserClassList{})
+}
@@ -0,0 +1,185 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	openshiftquota "github.com/openshift/api/quota/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+var (
+	// TeamQuotaBackend is the source of the team quotas, which is s3TeamQuota, openshift or disabled
+	TeamQuotaBackend = consts.TeamQuotaBackendOpenShift
+	// TeamLabel is the label whose value is the team of a namespace in the openshift backend
+	TeamLabel = consts.LabelTeam
+)
+
+// TeamQuota is the quota of a team, which is summed over the S3UserClaims of its namespaces
+// +kubebuilder:object:generate=false
+type TeamQuota struct {
+	// Name is the name of the team, which is the name of its S3TeamQuota or ClusterResourceQuota
+	Name string
+	// Hard is the hard limits of the S3 resources
+	Hard v1.ResourceList
+	// Namespaces are the namespaces of the team
+	Namespaces []string
+}
+
+// GetTeamQuotas returns the team quotas which limit the S3UserClaims of the namespace. ErrClusterQuotaNotDefined is
+// returned if no team quota limits the namespace, and no team quota is returned if the team quotas are disabled.
+func GetTeamQuotas(ctx context.Context, reader client.Reader, namespace string) ([]TeamQuota, error) {
+	switch TeamQuotaBackend {
+	case consts.TeamQuotaBackendDisabled:
+		return nil, nil
+	case consts.TeamQuotaBackendS3TeamQuota:
+		return getS3TeamQuotas(ctx, reader, namespace)
+	default:
+		return getClusterResourceQuotas(ctx, reader, namespace)
+	}
+}
+
+// ListTeamQuotas returns all the team quotas
+func ListTeamQuotas(ctx context.Context, reader client.Reader) ([]TeamQuota, error) {
+	switch TeamQuotaBackend {
+	case consts.TeamQuotaBackendDisabled:
+		return nil, nil
+	case consts.TeamQuotaBackendS3TeamQuota:
+		s3TeamQuotaList := &S3TeamQuotaList{}
+		if err := reader.List(ctx, s3TeamQuotaList); err != nil {
+			return nil, fmt.Errorf("failed to list s3TeamQuotas, %w", err)
+		}
+		teamQuotas := make([]TeamQuota, 0, len(s3TeamQuotaList.Items))
+		for _, s3TeamQuota := range s3TeamQuotaList.Items {
+			teamQuota, err := newS3TeamQuota(ctx, reader, &s3TeamQuota)
+			if err != nil {
+				return nil, err
+			}
+			teamQuotas = append(teamQuotas, *teamQuota)
+		}
+		return teamQuotas, nil
+	default:
+		clusterQuotaList := &openshiftquota.ClusterResourceQuotaList{}
+		if err := reader.List(ctx, clusterQuotaList); err != nil {
+			return nil, fmt.Errorf("failed to list cluster resource quotas, %w", err)
+		}
+		teamQuotas := make([]TeamQuota, 0, len(clusterQuotaList.Items))
+		for _, clusterQuota := range clusterQuotaList.Items {
+			namespaces, err := findTeamNamespaces(ctx, reader, clusterQuota.Name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find team namespaces, %w", err)
+			}
+			teamQuotas = append(teamQuotas, TeamQuota{
+				Name:       clusterQuota.Name,
+				Hard:       clusterQuota.Spec.Quota.Hard,
+				Namespaces: namespaces,
+			})
+		}
+		return teamQuotas, nil
+	}
+}
+
+// getS3TeamQuotas returns the S3TeamQuotas whose namespace selector selects the namespace
+func getS3TeamQuotas(ctx context.Context, reader client.Reader, namespace string) ([]TeamQuota, error) {
+	ns := &v1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, fmt.Errorf("failed to get namespace, %w", err)
+	}
+	s3TeamQuotaList := &S3TeamQuotaList{}
+	if err := reader.List(ctx, s3TeamQuotaList); err != nil {
+		return nil, fmt.Errorf("failed to list s3TeamQuotas, %w", err)
+	}
+
+	var teamQuotas []TeamQuota
+	for _, s3TeamQuota := range s3TeamQuotaList.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&s3TeamQuota.Spec.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector of s3TeamQuota %s, %w", s3TeamQuota.Name, err)
+		}
+		if !selector.Matches(labels.Set(ns.Labels)) {
+			continue
+		}
+		teamQuota, err := newS3TeamQuota(ctx, reader, &s3TeamQuota)
+		if err != nil {
+			return nil, err
+		}
+		teamQuotas = append(teamQuotas, *teamQuota)
+	}
+	if len(teamQuotas) == 0 {
+		return nil, fmt.Errorf("%w, namespace=%s", consts.ErrClusterQuotaNotDefined, namespace)
+	}
+	return teamQuotas, nil
+}
+
+func newS3TeamQuota(ctx context.Context, reader client.Reader, s3TeamQuota *S3TeamQuota) (*TeamQuota, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&s3TeamQuota.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector of s3TeamQuota %s, %w", s3TeamQuota.Name, err)
+	}
+	namespaceList := &v1.NamespaceList{}
+	if err := reader.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces, %w", err)
+	}
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for _, ns := range namespaceList.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	return &TeamQuota{Name: s3TeamQuota.Name, Hard: s3TeamQuota.Spec.Hard, Namespaces: namespaces}, nil
+}
+
+// getClusterResourceQuotas returns the ClusterResourceQuota named after the team label of the namespace
+func getClusterResourceQuotas(ctx context.Context, reader client.Reader, namespace string) ([]TeamQuota, error) {
+	team, err := findTeam(ctx, reader, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find team, %w", err)
+	}
+	clusterQuota := &openshiftquota.ClusterResourceQuota{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: team}, clusterQuota); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w, team=%s", consts.ErrClusterQuotaNotDefined, team)
+		}
+		return nil, fmt.Errorf("failed to get clusterQuota, %w", err)
+	}
+	namespaces, err := findTeamNamespaces(ctx, reader, team)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find team namespaces, %w", err)
+	}
+	return []TeamQuota{{Name: team, Hard: clusterQuota.Spec.Quota.Hard, Namespaces: namespaces}}, nil
+}
+
+func findTeam(ctx context.Context, reader client.Reader, namespace string) (string, error) {
+	ns := &v1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return "", fmt.Errorf("failed to get namespace, %w", err)
+	}
+
+	team, ok := ns.ObjectMeta.Labels[TeamLabel]
+	if !ok {
+		return "", fmt.Errorf("%w, namespace %s doesn't have the team label: %s", consts.ErrClusterQuotaNotDefined,
+			ns.ObjectMeta.Name, TeamLabel)
+	}
+
+	return team, nil
+}
+
+func findTeamNamespaces(ctx context.Context, reader client.Reader, team string) ([]string, error) {
+	var namespaces []string
+
+	namespaceList := &v1.NamespaceList{}
+	if err := reader.List(ctx, namespaceList, client.MatchingLabels{TeamLabel: team}); err != nil {
+		return namespaces, fmt.Errorf("failed to list namespaces, %w", err)
+	}
+
+	for _, ns := range namespaceList.Items {
+		namespaces = append(namespaces, ns.ObjectMeta.Name)
+	}
+
+	return namespaces, nil
+}
@@ -1,6 +1,11 @@
 package v1alpha1
 
-import "k8s.io/apimachinery/pkg/api/resource"
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
 
 // UserQuota specifies the quota for a user in Ceph
 type UserQuota struct {
@@ -12,15 +17,266 @@ type UserQuota struct {
 	MaxBuckets int64 `json:"maxBuckets,omitempty"`
 }
 
+// BucketQuota specifies the quota for a bucket in Ceph, an unset limit means the bucket is only limited by its user
+type BucketQuota struct {
+	// max number of bytes the bucket can store
+	MaxSize resource.Quantity `json:"maxSize,omitempty"`
+	// max number of objects the bucket can store
+	MaxObjects resource.Quantity `json:"maxObjects,omitempty"`
+}
+
+// BucketNameRandomLength is the length of the random part of a generated bucket name
+const BucketNameRandomLength = 8
+
+// BucketNameGeneration specifies the template of a generated bucket name which is made of the prefix, a random part
+// and the suffix. {namespace} and {name} in the prefix and the suffix are replaced by the namespace and the name of
+// the S3Bucket.
+type BucketNameGeneration struct {
+	// +kubebuilder:validation:Optional
+	Prefix string `json:"prefix,omitempty"`
+	// +kubebuilder:validation:Optional
+	Suffix string `json:"suffix,omitempty"`
+}
+
+// BucketImportStatus records the import of an existing bucket
+type BucketImportStatus struct {
+	// name of the imported bucket
+	Bucket string `json:"bucket"`
+	// full ID of the Ceph user which owned the bucket before the import
+	// +kubebuilder:validation:Optional
+	PreviousOwner string `json:"previousOwner,omitempty"`
+	// time which the bucket was imported at
+	ImportTime metav1.Time `json:"importTime"`
+}
+
+// BucketLifecycle specifies the lifecycle configuration of a bucket
+type BucketLifecycle struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=1000
+	Rules []LifecycleRule `json:"rules,omitempty"`
+}
+
+// LifecycleRule specifies the actions which are taken on the objects matching the filter of the rule
+type LifecycleRule struct {
+	// unique identifier of the rule
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=255
+	ID string `json:"id"`
+	// whether the rule is kept without being applied
+	// +kubebuilder:validation:Optional
+	Disabled bool `json:"disabled,omitempty"`
+	// objects which the rule applies to, the rule applies to all objects if it's not set
+	// +kubebuilder:validation:Optional
+	Filter *LifecycleFilter `json:"filter,omitempty"`
+	// number of days after creation which the objects expire after
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	ExpirationDays *int64 `json:"expirationDays,omitempty"`
+	// number of days after becoming noncurrent which the object versions expire after
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	NoncurrentVersionExpirationDays *int64 `json:"noncurrentVersionExpirationDays,omitempty"`
+	// number of days after initiation which the incomplete multipart uploads are aborted after
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	AbortIncompleteMultipartUploadDays *int64 `json:"abortIncompleteMultipartUploadDays,omitempty"`
+	// transitions of the objects to other storage classes
+	// +kubebuilder:validation:Optional
+	Transitions []LifecycleTransition `json:"transitions,omitempty"`
+}
+
+// LifecycleFilter specifies the objects which a lifecycle rule applies to
+type LifecycleFilter struct {
+	// key prefix of the objects
+	// +kubebuilder:validation:Optional
+	Prefix string `json:"prefix,omitempty"`
+	// tags which the objects must have all of them
+	// +kubebuilder:validation:Optional
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// LifecycleTransition specifies the transition of objects to a storage class
+type LifecycleTransition struct {
+	// number of days after creation which the objects are transitioned after
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Days int64 `json:"days"`
+	// storage class which the objects are transitioned to
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	StorageClass string `json:"storageClass"`
+}
+
+// BucketCORS specifies the CORS configuration of a bucket
+type BucketCORS struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	Rules []CORSRule `json:"rules"`
+}
+
+// CORSRule specifies the cross-origin requests which are allowed on a bucket
+type CORSRule struct {
+	// origins which the requests are allowed from, e.g. https://example.com or *
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	AllowedOrigins []string `json:"allowedOrigins"`
+	// HTTP methods which are allowed
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	AllowedMethods []CORSMethod `json:"allowedMethods"`
+	// headers which are allowed in the preflight requests
+	// +kubebuilder:validation:Optional
+	AllowedHeaders []string `json:"allowedHeaders,omitempty"`
+	// headers in the response which the browsers are allowed to access
+	// +kubebuilder:validation:Optional
+	ExposeHeaders []string `json:"exposeHeaders,omitempty"`
+	// number of seconds which the browsers can cache the preflight response for
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxAgeSeconds *int64 `json:"maxAgeSeconds,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=GET;PUT;POST;DELETE;HEAD
+type CORSMethod string
+
+// UserUsage specifies the actual usage of a user in Ceph
+type UserUsage struct {
+	// number of bytes the user stores
+	Size resource.Quantity `json:"size,omitempty"`
+	// number of objects the user stores
+	Objects resource.Quantity `json:"objects,omitempty"`
+	// number of buckets the user owns
+	Buckets int64 `json:"buckets,omitempty"`
+	// used percentage of the max size quota
+	SizePercentage int64 `json:"sizePercentage,omitempty"`
+	// used percentage of the max objects quota
+	ObjectsPercentage int64 `json:"objectsPercentage,omitempty"`
+	// used percentage of the max buckets quota
+	BucketsPercentage int64 `json:"bucketsPercentage,omitempty"`
+	// time at which the usage was retrieved from Ceph
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
 // +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$
 type Subuser string
 type SubuserBinding struct {
 	// name of the subuser
 	// +kubebuilder:validation:Required
 	Name string `json:"name"`
-	// access of the subuser which can be read or write
+	// access of the subuser which can be read, write, list (listing only) or writeonly (uploading only)
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=read
-	// +kubebuilder:validation:Enum=read;write
+	// +kubebuilder:validation:Enum=read;write;list;writeonly
 	Access string `json:"access,omitempty"`
+	// object key prefixes which the access is limited to, the access covers the whole bucket if it's not set
+	// +kubebuilder:validation:Optional
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// BucketPublicAccess grants anonymous read access on the objects of a bucket
+type BucketPublicAccess struct {
+	// mode of the public access which can be none, read-objects (every object of the bucket) or read-prefixes
+	// (the objects under the prefixes). Listing the bucket is never public.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=none
+	// +kubebuilder:validation:Enum=none;read-objects;read-prefixes
+	Mode string `json:"mode,omitempty"`
+	// object key prefixes which are public in the read-prefixes mode
+	// +kubebuilder:validation:Optional
+	Prefixes []string `json:"prefixes,omitempty"`
+}
+
+// IsEnabled reports whether the public access grants anything
+func (pa *BucketPublicAccess) IsEnabled() bool {
+	return pa != nil && pa.Mode != "" && pa.Mode != consts.PublicAccessModeNone
+}
+
+// PublicAccessPolicy restricts the namespaces whose S3Buckets can be public
+type PublicAccessPolicy struct {
+	// namespaces whose S3Buckets can be public, "*" allows every namespace. No S3Bucket can be public if it's empty.
+	// +kubebuilder:validation:Optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// Allows reports whether the S3Buckets of the namespace can be public
+func (pap *PublicAccessPolicy) Allows(namespace string) bool {
+	if pap == nil {
+		return false
+	}
+	for _, allowedNamespace := range pap.AllowedNamespaces {
+		if allowedNamespace == "*" || allowedNamespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// AdoptionPolicy restricts the namespaces whose S3UserClaims can adopt the Ceph users of other tenants and whose
+// S3Buckets can import the buckets of other tenants. The users and the buckets of the tenant of a namespace can always
+// be adopted by its claims and buckets.
+type AdoptionPolicy struct {
+	// namespaces which can adopt the users and import the buckets of any tenant, "*" allows every namespace
+	// +kubebuilder:validation:Optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// Allows reports whether the namespace can adopt the users and import the buckets of any tenant
+func (ap *AdoptionPolicy) Allows(namespace string) bool {
+	if ap == nil {
+		return false
+	}
+	for _, allowedNamespace := range ap.AllowedNamespaces {
+		if allowedNamespace == "*" || allowedNamespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyRotation configures the rotation of the S3 keys of a user and its subusers.
+// Keys are also rotated whenever the value of the s3.snappcloud.io/rotate-keys annotation changes.
+type KeyRotation struct {
+	// interval between two automatic rotations, keys are only rotated on demand if it's not set
+	// +kubebuilder:validation:Optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// duration which the previous keys stay valid after a rotation
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="1h"
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// KeyRotationStatus specifies the observed state of the key rotation
+type KeyRotationStatus struct {
+	// time of the last rotation
+	// +kubebuilder:validation:Optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// value of the rotate-keys annotation which triggered the last rotation
+	// +kubebuilder:validation:Optional
+	LastRotationTrigger string `json:"lastRotationTrigger,omitempty"`
+	// keys replaced by a rotation which are revoked when their grace period is over
+	// +kubebuilder:validation:Optional
+	RetiredKeys []RetiredKey `json:"retiredKeys,omitempty"`
+	// keys of the rotation in progress, which are recorded before they're created
+	// +kubebuilder:validation:Optional
+	PendingKeys []PendingKey `json:"pendingKeys,omitempty"`
+}
+
+// PendingKey is a new key of a rotation which may not be created yet
+type PendingKey struct {
+	// Ceph user or subuser the key belongs to
+	User string `json:"user"`
+	// access key of the key
+	AccessKey string `json:"accessKey"`
+}
+
+// RetiredKey is a replaced key which is still valid until it's revoked
+type RetiredKey struct {
+	// Ceph user or subuser the key belongs to
+	User string `json:"user"`
+	// access key of the key
+	AccessKey string `json:"accessKey"`
+	// time after which the key is revoked
+	RevokeAfter metav1.Time `json:"revokeAfter"`
 }
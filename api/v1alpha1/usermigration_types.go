@@ -0,0 +1,145 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UserMigrationSpec defines the user and the buckets which are migrated. It can't be changed once created.
+type UserMigrationSpec struct {
+	// S3UserClaim whose Ceph user and buckets are migrated
+	// +kubebuilder:validation:Required
+	SourceClaim ClaimReference `json:"sourceClaim"`
+
+	// namespace which the user is migrated to
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	TargetNamespace string `json:"targetNamespace"`
+
+	// name of the S3UserClaim which is created in the target namespace, defaults to the name of the source claim
+	// +kubebuilder:validation:Optional
+	TargetClaimName string `json:"targetClaimName,omitempty"`
+
+	// buckets which are linked to the target user, all the buckets of the source user are migrated if it's not set
+	// +kubebuilder:validation:Optional
+	Buckets []string `json:"buckets,omitempty"`
+}
+
+// ClaimReference references an S3UserClaim
+type ClaimReference struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// UserMigrationStatus defines the observed progress of the migration
+type UserMigrationStatus struct {
+	// +kubebuilder:validation:Optional
+	Phase UserMigrationPhase `json:"phase,omitempty"`
+
+	// details of the current phase, e.g. the reason of the failure
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// full ID of the source Ceph user
+	// +kubebuilder:validation:Optional
+	SourceUser string `json:"sourceUser,omitempty"`
+
+	// full ID of the target Ceph user
+	// +kubebuilder:validation:Optional
+	TargetUser string `json:"targetUser,omitempty"`
+
+	// buckets which are being migrated
+	// +kubebuilder:validation:Optional
+	Buckets []string `json:"buckets,omitempty"`
+
+	// buckets which are linked to the target user
+	// +kubebuilder:validation:Optional
+	LinkedBuckets []string `json:"linkedBuckets,omitempty"`
+
+	// S3Buckets which are moved to the target namespace
+	// +kubebuilder:validation:Optional
+	MovedS3Buckets []string `json:"movedS3Buckets,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// UserMigrationPhase is the phase of a UserMigration
+type UserMigrationPhase string
+
+const (
+	UserMigrationPhasePending        UserMigrationPhase = "Pending"
+	UserMigrationPhaseCreatingTarget UserMigrationPhase = "CreatingTarget"
+	UserMigrationPhaseLinkingBuckets UserMigrationPhase = "LinkingBuckets"
+	UserMigrationPhaseMovingBuckets  UserMigrationPhase = "MovingBuckets"
+	UserMigrationPhaseDeletingSource UserMigrationPhase = "DeletingSource"
+	UserMigrationPhaseRollingBack    UserMigrationPhase = "RollingBack"
+	UserMigrationPhaseSucceeded      UserMigrationPhase = "Succeeded"
+	UserMigrationPhaseFailed         UserMigrationPhase = "Failed"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster,shortName=s3migration
+// +kubebuilder:printcolumn:name="SOURCE NS",type=string,JSONPath=`.spec.sourceClaim.namespace`
+// +kubebuilder:printcolumn:name="SOURCE CLAIM",type=string,JSONPath=`.spec.sourceClaim.name`
+// +kubebuilder:printcolumn:name="TARGET NS",type=string,JSONPath=`.spec.targetNamespace`
+// +kubebuilder:printcolumn:name="PHASE",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// User Migration moves a Ceph user and its buckets to another namespace. It runs once like a job.
+type UserMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UserMigrationSpec   `json:"spec,omitempty"`
+	Status UserMigrationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// UserMigrationList contains a list of UserMigration
+type UserMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []UserMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&UserMigration{}, &UserMigrationList{})
+}
+
+// GetTargetClaimName returns the name of the target S3UserClaim
+func (um *UserMigration) GetTargetClaimName() string {
+	if um.Spec.TargetClaimName != "" {
+		return um.Spec.TargetClaimName
+	}
+	return um.Spec.SourceClaim.Name
+}
+
+// IsFinished reports whether the migration has reached a terminal phase
+func (um *UserMigration) IsFinished() bool {
+	return um.Status.Phase == UserMigrationPhaseSucceeded || um.Status.Phase == UserMigrationPhaseFailed
+}
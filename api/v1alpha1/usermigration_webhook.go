@@ -0,0 +1,101 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// log is for logging in this package.
+var usermigrationlog = logf.Log.WithName("usermigration-resource")
+
+func (um *UserMigration) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	runtimeClient = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(um).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-s3-snappcloud-io-v1alpha1-usermigration,mutating=false,failurePolicy=fail,sideEffects=None,groups=s3.snappcloud.io,resources=usermigrations,verbs=create;update,versions=v1alpha1,name=vusermigration.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &UserMigration{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (um *UserMigration) ValidateCreate() error {
+	usermigrationlog.Info("validate create", "name", um.Name)
+	allErrs := field.ErrorList{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ValidationTimeout)
+	defer cancel()
+
+	sourceClaim := um.Spec.SourceClaim
+	err := runtimeClient.Get(ctx, types.NamespacedName{Namespace: sourceClaim.Namespace, Name: sourceClaim.Name},
+		&S3UserClaim{})
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("sourceClaim"), sourceClaim,
+			consts.SourceClaimNotFoundErrMessage))
+	}
+
+	if um.Spec.TargetNamespace == sourceClaim.Namespace {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("targetNamespace"),
+			um.Spec.TargetNamespace, consts.TargetNamespaceSameAsSourceErrMessage))
+	} else if err := runtimeClient.Get(ctx, types.NamespacedName{Name: um.Spec.TargetNamespace},
+		&v1.Namespace{}); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("targetNamespace"),
+			um.Spec.TargetNamespace, consts.TargetNamespaceNotFoundErrMessage))
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(um.GroupVersionKind().GroupKind(), um.Name, allErrs)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (um *UserMigration) ValidateUpdate(old runtime.Object) error {
+	usermigrationlog.Info("validate update", "name", um.Name)
+
+	oldUserMigration, ok := old.(*UserMigration)
+	if !ok {
+		usermigrationlog.Info("invalid object passed as old userMigration", "type", old.GetObjectKind())
+		return fmt.Errorf(internalErrorMessage)
+	}
+	if apiequality.Semantic.DeepEqual(um.Spec, oldUserMigration.Spec) {
+		return nil
+	}
+	return apierrors.NewInvalid(um.GroupVersionKind().GroupKind(), um.Name, field.ErrorList{
+		field.Forbidden(field.NewPath("spec"), consts.UserMigrationImmutableErrMessage),
+	})
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (um *UserMigration) ValidateDelete() error {
+	return nil
+}
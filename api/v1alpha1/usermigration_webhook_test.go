@@ -0,0 +1,79 @@
+package v1alpha1
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+var _ = Describe("UserMigration webhook", Ordered, ContinueOnFailure, func() {
+	const (
+		sourceNamespace   = "usermigration-webhook-source"
+		targetNamespace   = "usermigration-webhook-target"
+		userMigrationName = "test-usermigration"
+	)
+
+	var ctx = context.Background()
+
+	BeforeAll(func() {
+		for _, namespace := range []string{sourceNamespace, targetNamespace} {
+			Expect(k8sClient.Create(ctx, &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: namespace},
+			})).To(Succeed())
+		}
+	})
+
+	Context("When creating UserMigration", func() {
+		It("Should deny creating if the source claim doesn't exist", func() {
+			userMigration := getUserMigration(userMigrationName, sourceNamespace, "missing-s3userclaim",
+				targetNamespace)
+
+			err := k8sClient.Create(ctx, userMigration)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.SourceClaimNotFoundErrMessage))
+		})
+
+		It("Should deny creating if the target namespace is the namespace of the source claim", func() {
+			userMigration := getUserMigration(userMigrationName, sourceNamespace, "test-s3userclaim", sourceNamespace)
+
+			err := k8sClient.Create(ctx, userMigration)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.TargetNamespaceSameAsSourceErrMessage))
+		})
+
+		It("Should deny creating if the target namespace doesn't exist", func() {
+			userMigration := getUserMigration(userMigrationName, sourceNamespace, "test-s3userclaim",
+				"missing-namespace")
+
+			err := k8sClient.Create(ctx, userMigration)
+			var apiStatus apierrors.APIStatus
+			Expect(goerrors.As(err, &apiStatus)).To(BeTrue())
+			Expect(apiStatus.Status().Code).To(Equal(int32(http.StatusUnprocessableEntity)))
+			Expect(apiStatus.Status().Message).To(ContainSubstring(consts.TargetNamespaceNotFoundErrMessage))
+		})
+	})
+})
+
+func getUserMigration(name, sourceNamespace, sourceClaim, targetNamespace string) *UserMigration {
+	return &UserMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: UserMigrationSpec{
+			SourceClaim:     ClaimReference{Namespace: sourceNamespace, Name: sourceClaim},
+			TargetNamespace: targetNamespace,
+		},
+	}
+}
@@ -111,12 +111,20 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 
 	ValidationTimeout = time.Duration(config.DefaultConfig.ValidationWebhookTimeoutSeconds) * time.Second
+	DefaultS3UserClass = config.DefaultConfig.S3UserClass
+	DefaultClusterName = config.DefaultConfig.ClusterName
 	err = (&S3UserClaim{}).SetupWebhookWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
 	err = (&S3Bucket{}).SetupWebhookWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = (&UserMigration{}).SetupWebhookWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = (&S3BucketAccess{}).SetupWebhookWithManager(mgr)
+	Expect(err).NotTo(HaveOccurred())
+
 	//+kubebuilder:scaffold:webhook
 
 	go func() {
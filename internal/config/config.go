@@ -1,23 +1,102 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/providers/structs"
 	koanf "github.com/knadh/koanf/v2"
+
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
 )
 
 type Rgw struct {
 	Endpoint  string `koanf:"endpoint"`
 	AccessKey string `koanf:"accessKey"`
 	SecretKey string `koanf:"secretKey"`
+	Region    string `koanf:"region"`
+	TLS       *TLS   `koanf:"tls"`
+	// TimeoutSeconds is the timeout of the requests to RGW
+	TimeoutSeconds int `koanf:"timeoutSeconds"`
+	// MaxRetries is the max number of retries of the requests to RGW which fail on the network or with a server error
+	MaxRetries int `koanf:"maxRetries"`
+}
+
+type TLS struct {
+	// InsecureSkipVerify skips the verification of the certificate of RGW
+	InsecureSkipVerify bool `koanf:"insecureSkipVerify"`
+	// CAFile is the path of the PEM bundle of the CAs which verify the certificate of RGW, the system CAs are used
+	// if it's empty
+	CAFile string `koanf:"caFile"`
+	// CertFile and KeyFile are the paths of the PEM client certificate and key which are presented to RGW
+	CertFile string `koanf:"certFile"`
+	KeyFile  string `koanf:"keyFile"`
+}
+
+type PublicAccess struct {
+	// AllowedNamespaces are the namespaces whose S3Buckets of the default S3UserClass can be public, "*" allows every
+	// namespace
+	AllowedNamespaces []string `koanf:"allowedNamespaces"`
+}
+
+type Adoption struct {
+	// AllowedNamespaces are the namespaces whose S3UserClaims and S3Buckets of the default S3UserClass can adopt the
+	// users and import the buckets of other tenants, "*" allows every namespace
+	AllowedNamespaces []string `koanf:"allowedNamespaces"`
+}
+
+type Quota struct {
+	// Mode is requested, which enforces the sum of the requested quotas against the hard limits, or overcommit, which
+	// enforces the actual usage of the users against the hard limits and the requested quotas against the hard limits
+	// multiplied by OvercommitRatio
+	Mode            string  `koanf:"mode"`
+	OvercommitRatio float64 `koanf:"overcommitRatio"`
+	// WarningThresholdPercent is the percentage of the hard limits whose consumption is warned about on admission and
+	// with events, zero disables the warnings
+	WarningThresholdPercent int `koanf:"warningThresholdPercent"`
+}
+
+type TeamQuota struct {
+	// Backend is the source of the quotas of the teams, which is s3TeamQuota for the S3TeamQuota objects, openshift
+	// for the ClusterResourceQuota objects named after the teams, or disabled
+	Backend string `koanf:"backend"`
+	// TeamLabel is the label whose value is the team of a namespace in the openshift backend
+	TeamLabel string `koanf:"teamLabel"`
+}
+
+type Controller struct {
+	// MaxConcurrentReconciles is the number of workers reconciling objects of the controller in parallel
+	MaxConcurrentReconciles int `koanf:"maxConcurrentReconciles"`
+}
+
+type S3BucketController struct {
+	// MaxConcurrentReconciles is the number of workers reconciling objects of the controller in parallel
+	MaxConcurrentReconciles int `koanf:"maxConcurrentReconciles"`
+	// ResyncPeriodSeconds is the period after which a successfully reconciled S3Bucket is reconciled again to detect
+	// the changes made to its bucket out of band, zero disables the resync
+	ResyncPeriodSeconds int `koanf:"resyncPeriodSeconds"`
+}
+
+type Controllers struct {
+	S3UserClaim    *Controller         `koanf:"s3UserClaim"`
+	S3Bucket       *S3BucketController `koanf:"s3Bucket"`
+	UserMigration  *Controller         `koanf:"userMigration"`
+	S3BucketAccess *Controller         `koanf:"s3BucketAccess"`
 }
 
 type Config struct {
-	S3UserClass                     string `koanf:"s3UserClass"`
-	ClusterName                     string `koanf:"clusterName"`
-	ValidationWebhookTimeoutSeconds int    `koanf:"validationWebhookTimeoutSeconds"`
-	Rgw                             *Rgw   `koanf:"rgw"`
+	S3UserClass                     string        `koanf:"s3UserClass"`
+	ClusterName                     string        `koanf:"clusterName"`
+	ValidationWebhookTimeoutSeconds int           `koanf:"validationWebhookTimeoutSeconds"`
+	UsageSyncIntervalSeconds        int           `koanf:"usageSyncIntervalSeconds"`
+	Rgw                             *Rgw          `koanf:"rgw"`
+	PublicAccess                    *PublicAccess `koanf:"publicAccess"`
+	Adoption                        *Adoption     `koanf:"adoption"`
+	Quota                           *Quota        `koanf:"quota"`
+	TeamQuota                       *TeamQuota    `koanf:"teamQuota"`
+	Controllers                     *Controllers  `koanf:"controllers"`
 }
 
 var (
@@ -25,10 +104,32 @@ var (
 		S3UserClass:                     "ceph-default",
 		ClusterName:                     "okd4-main",
 		ValidationWebhookTimeoutSeconds: 10,
+		UsageSyncIntervalSeconds:        300,
 		Rgw: &Rgw{
-			Endpoint:  "http://127.0.0.1:8000",
-			AccessKey: "2262XNX11FZRR44XWIRD",
-			SecretKey: "rmtuS1Uj1bIC08QFYGW18GfSHAbkPqdsuYynNudw",
+			Endpoint:       "http://127.0.0.1:8000",
+			AccessKey:      "2262XNX11FZRR44XWIRD",
+			SecretKey:      "rmtuS1Uj1bIC08QFYGW18GfSHAbkPqdsuYynNudw",
+			Region:         "us-east-1",
+			TLS:            &TLS{},
+			TimeoutSeconds: 15,
+			MaxRetries:     5,
+		},
+		PublicAccess: &PublicAccess{},
+		Adoption:     &Adoption{},
+		Quota: &Quota{
+			Mode:                    consts.QuotaModeRequested,
+			OvercommitRatio:         2,
+			WarningThresholdPercent: 80,
+		},
+		TeamQuota: &TeamQuota{
+			Backend:   consts.TeamQuotaBackendOpenShift,
+			TeamLabel: consts.LabelTeam,
+		},
+		Controllers: &Controllers{
+			S3UserClaim:    &Controller{MaxConcurrentReconciles: 1},
+			S3Bucket:       &S3BucketController{MaxConcurrentReconciles: 1, ResyncPeriodSeconds: 600},
+			UserMigration:  &Controller{MaxConcurrentReconciles: 1},
+			S3BucketAccess: &Controller{MaxConcurrentReconciles: 1},
 		},
 	}
 )
@@ -52,3 +153,31 @@ func GetConfig(configPath string) (*Config, error) {
 
 	return cfg, nil
 }
+
+// Validate checks the parts of the config which can't be checked by their types
+func (c *Config) Validate() error {
+	switch c.Quota.Mode {
+	case consts.QuotaModeRequested:
+	case consts.QuotaModeOvercommit:
+		if c.Quota.OvercommitRatio < 1 {
+			return errors.New("quota overcommit ratio must be at least 1")
+		}
+		// The actual usage of the users is only known when it's synced from RGW
+		if c.UsageSyncIntervalSeconds <= 0 {
+			return errors.New("the overcommit quota mode requires the usage sync")
+		}
+	default:
+		return fmt.Errorf("invalid quota mode %q", c.Quota.Mode)
+	}
+
+	switch c.TeamQuota.Backend {
+	case consts.TeamQuotaBackendS3TeamQuota, consts.TeamQuotaBackendDisabled:
+	case consts.TeamQuotaBackendOpenShift:
+		if c.TeamQuota.TeamLabel == "" {
+			return errors.New("the openshift team quota backend requires the team label")
+		}
+	default:
+		return fmt.Errorf("invalid team quota backend %q", c.TeamQuota.Backend)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package s3bucket
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/opdev/subreconciler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/s3userclass"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// importBucket links the existing bucket to the Ceph user of the s3UserRef if another user owns it. The import is
+// recorded in the status, so it's done only once.
+func (r *reconcileRequest) importBucket(ctx context.Context) (*ctrl.Result, error) {
+	existingBucket := r.s3Bucket.Spec.ExistingBucket
+	if existingBucket == "" || r.bucketImport != nil {
+		return subreconciler.ContinueReconciling()
+	}
+	logger := r.logger.WithValues("existingBucket", existingBucket)
+
+	bucket, err := r.rgwClient.GetBucketInfo(ctx, admin.Bucket{Bucket: existingBucket})
+	switch {
+	case goerrors.Is(err, admin.ErrNoSuchBucket):
+		logger.Error(err, "failed to find the bucket to import")
+		r.setCondition(consts.ConditionTypeBucketSynced, consts.ErrBucketNotFound)
+		r.updateBucketStatus(ctx, false, consts.ErrBucketNotFound.Error(), r.s3Bucket.Status.Policy)
+		return subreconciler.Requeue()
+	case err != nil:
+		logger.Error(err, "failed to get the bucket info")
+		r.setCondition(consts.ConditionTypeBucketSynced, fmt.Errorf("failed to get the bucket info, %w", err))
+		r.updateBucketStatus(ctx, false, err.Error(), r.s3Bucket.Status.Policy)
+		return subreconciler.Requeue()
+	}
+
+	if bucket.Owner != r.cephUserFullId {
+		if err := r.checkBucketOwner(ctx, bucket.Owner); err != nil {
+			logger.Error(err, "refused to import the bucket", "owner", bucket.Owner)
+			r.setCondition(consts.ConditionTypeBucketSynced, err)
+			r.updateBucketStatus(ctx, false, err.Error(), r.s3Bucket.Status.Policy)
+			return subreconciler.Requeue()
+		}
+
+		logger.Info("linking the bucket to the user", "owner", bucket.Owner, "userId", r.cephUserFullId)
+		if err := r.rgwClient.LinkBucket(ctx, admin.BucketLinkInput{
+			Bucket:   existingBucket,
+			BucketID: bucket.ID,
+			UID:      r.cephUserFullId,
+		}); err != nil {
+			logger.Error(err, "failed to link the bucket")
+			r.setCondition(consts.ConditionTypeBucketSynced, fmt.Errorf("failed to link the bucket, %w", err))
+			r.updateBucketStatus(ctx, false, err.Error(), r.s3Bucket.Status.Policy)
+			return subreconciler.Requeue()
+		}
+	}
+
+	r.bucketImport = &s3v1alpha1.BucketImportStatus{
+		Bucket:        existingBucket,
+		PreviousOwner: bucket.Owner,
+		ImportTime:    metav1.Now(),
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// checkBucketOwner refuses to take over the buckets of the tenants which the namespace isn't allowed to import from
+// and the buckets of the users which are bound to an S3User, unless the S3User is retained
+func (r *reconcileRequest) checkBucketOwner(ctx context.Context, owner string) error {
+	ownerTenant, _ := s3userclass.SplitUserID(owner)
+	if !r.s3UserClass.AllowsAdoption(r.s3Bucket.Namespace, ownerTenant) {
+		return fmt.Errorf("%w: %s", consts.ErrBucketOwnedByAnotherTenant, ownerTenant)
+	}
+
+	s3UserList := &s3v1alpha1.S3UserList{}
+	if err := r.List(ctx, s3UserList); err != nil {
+		return fmt.Errorf("failed to list s3Users, %w", err)
+	}
+	for i := range s3UserList.Items {
+		s3User := &s3UserList.Items[i]
+		if r.classResolver.Name(s3User.Spec.S3UserClass) != r.s3UserClass.Name {
+			continue
+		}
+		if r.s3UserClass.S3UserCephUserID(s3User) == owner && s3User.Status.RetainedTime == nil {
+			return fmt.Errorf("%w: %s", consts.ErrBucketOwnedByAnotherS3User, s3User.Name)
+		}
+	}
+	return nil
+}
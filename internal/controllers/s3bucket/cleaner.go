@@ -6,6 +6,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/opdev/subreconciler"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -13,7 +15,7 @@ import (
 )
 
 // Cleanup cleans up the provisioned resources for the s3Bucket object
-func (r *Reconciler) Cleanup(ctx context.Context) (ctrl.Result, error) {
+func (r *reconcileRequest) Cleanup(ctx context.Context) (ctrl.Result, error) {
 	// Do the actual reconcile work
 	subrecs := []subreconciler.Fn{
 		r.removeOrRetainBucket,
@@ -29,9 +31,10 @@ func (r *Reconciler) Cleanup(ctx context.Context) (ctrl.Result, error) {
 	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 }
 
-func (r *Reconciler) removeOrRetainBucket(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) removeOrRetainBucket(ctx context.Context) (*ctrl.Result, error) {
 	// Clean only if deletionPolicy is on Delete mode
-	if r.s3Bucket.Spec.S3DeletionPolicy == consts.DeletionPolicyRetain {
+	// The bucket is never created if its name isn't generated yet
+	if r.s3Bucket.Spec.S3DeletionPolicy == consts.DeletionPolicyRetain || r.s3BucketName == "" {
 		return subreconciler.ContinueReconciling()
 	}
 	err := r.s3Agent.DeleteBucket(r.s3BucketName)
@@ -44,14 +47,17 @@ func (r *Reconciler) removeOrRetainBucket(ctx context.Context) (*ctrl.Result, er
 			}
 		}
 		r.logger.Error(err, "failed to remove the bucket")
+		r.recorder.Eventf(r.s3Bucket, corev1.EventTypeWarning, consts.EventReasonDeletionBlocked,
+			"Failed to remove bucket %s: %s", r.s3BucketName, err.Error())
 		// update bucket status with failure reason; e.g. Bucket is not empty
+		r.setReadyCondition(metav1.ConditionFalse, consts.ConditionReasonDeletionFailed, err.Error())
 		r.updateBucketStatus(ctx, true, err.Error(), "unknown")
 		return subreconciler.Requeue()
 	}
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *Reconciler) removeCleanupFinalizer(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) removeCleanupFinalizer(ctx context.Context) (*ctrl.Result, error) {
 	if r.s3Bucket == nil {
 		return subreconciler.ContinueReconciling()
 	}
@@ -17,8 +17,15 @@ limitations under the License.
 package s3bucket
 
 import (
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
 )
@@ -26,8 +33,28 @@ import (
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&s3v1alpha1.S3Bucket{}).
-		// Set predicate to filter only generation change events.
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		// Set predicate to filter only generation change events, and annotation change events to resume paused
+		// buckets.
+		For(&s3v1alpha1.S3Bucket{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		// The accepted grants of the bucket are recorded in its policy
+		Watches(
+			&source.Kind{Type: &s3v1alpha1.S3BucketAccess{}},
+			handler.EnqueueRequestsFromMapFunc(s3BucketAccessToS3Bucket)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.maxConcurrentReconciles}).
 		Complete(r)
 }
+
+func s3BucketAccessToS3Bucket(object client.Object) []reconcile.Request {
+	s3BucketAccess, ok := object.(*s3v1alpha1.S3BucketAccess)
+	if !ok || s3BucketAccess.Spec.Grant == nil {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{
+			Namespace: s3BucketAccess.Namespace,
+			Name:      s3BucketAccess.Spec.Grant.S3BucketRef,
+		}},
+	}
+}
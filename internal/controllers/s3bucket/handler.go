@@ -3,13 +3,16 @@ package s3bucket
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"time"
 
+	"github.com/ceph/go-ceph/rgw/admin"
 	"github.com/go-logr/logr"
 	"github.com/opdev/subreconciler"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -20,49 +23,80 @@ import (
 	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
 	"github.com/snapp-incubator/ceph-s3-operator/internal/config"
 	"github.com/snapp-incubator/ceph-s3-operator/internal/s3_agent"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/s3userclass"
 	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
 )
 
 // S3BucketReconciler reconciles a S3Bucket object
 type Reconciler struct {
 	client.Client
-	scheme  *runtime.Scheme
-	logger  logr.Logger
-	s3Agent *s3_agent.S3Agent
-	// reconcile specific variables
+	scheme        *runtime.Scheme
+	classResolver *s3userclass.Resolver
+	recorder      record.EventRecorder
+	// configurations
+	maxConcurrentReconciles int
+	resyncPeriod            time.Duration
+}
+
+// reconcileRequest holds the state of a single reconciliation. A new one is created on each call to Reconcile
+// so that concurrent reconciles never share any mutable state.
+type reconcileRequest struct {
+	*Reconciler
+	logger    logr.Logger
+	s3Agent   *s3_agent.S3Agent
+	rgwClient *admin.API
+	// s3UserClass is the resolved class of the s3UserClaim referenced by the bucket
+	s3UserClass *s3userclass.Class
+
 	s3Bucket         *s3v1alpha1.S3Bucket
 	s3UserRef        string
 	s3BucketName     string
-	rgwEndpoint      string
-	clusterName      string
 	cephTenant       string
+	cephUserId       string
 	cephUserFullId   string
-	subuserAccessMap map[string]string
+	existingUser     string
 	bucketPolicy     string
+	bucketQuota      *s3v1alpha1.BucketQuota
+	bucketVersioning string
+	lifecycleApplied bool
+	corsApplied      bool
+	bucketImport     *s3v1alpha1.BucketImportStatus
+	conditions       []metav1.Condition
 }
 
 func NewReconciler(mgr manager.Manager, cfg *config.Config) *Reconciler {
 
 	return &Reconciler{
-		Client:      mgr.GetClient(),
-		scheme:      mgr.GetScheme(),
-		rgwEndpoint: cfg.Rgw.Endpoint,
-		clusterName: cfg.ClusterName,
+		Client:                  mgr.GetClient(),
+		scheme:                  mgr.GetScheme(),
+		classResolver:           s3userclass.NewResolver(mgr.GetClient(), cfg),
+		recorder:                mgr.GetEventRecorderFor("s3bucket-controller"),
+		maxConcurrentReconciles: cfg.Controllers.S3Bucket.MaxConcurrentReconciles,
+		resyncPeriod:            time.Duration(cfg.Controllers.S3Bucket.ResyncPeriodSeconds) * time.Second,
 	}
 }
 
 //+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3buckets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3buckets/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3buckets/finalizers,verbs=update
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3userclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3users,verbs=get;list;watch
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3bucketaccesses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	rr := &reconcileRequest{
+		Reconciler:   r,
+		logger:       log.FromContext(ctx),
+		s3Bucket:     &s3v1alpha1.S3Bucket{},
+		s3BucketName: req.Name,
+	}
+	return rr.reconcile(ctx, req)
+}
 
-	r.logger = log.FromContext(ctx)
-	r.s3Bucket = &s3v1alpha1.S3Bucket{}
-	r.s3BucketName = req.Name
-
+func (r *reconcileRequest) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Get s3Bucket object
 	switch err := r.Get(ctx, req.NamespacedName, r.s3Bucket); {
 	case apierrors.IsNotFound(err):
@@ -71,12 +105,25 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	case err != nil:
 		r.logger.Error(err, "failed to fetch object")
 		return subreconciler.Evaluate(subreconciler.Requeue())
+	case r.s3Bucket.DeletionTimestamp == nil && r.s3Bucket.Annotations[consts.AnnotationPaused] != "":
+		r.logger.Info("S3Bucket is paused", "pausedBy", r.s3Bucket.Annotations[consts.AnnotationPaused])
+		return ctrl.Result{}, nil
 	default:
 		r.s3UserRef = r.s3Bucket.Spec.S3UserRef
+		r.conditions = r.s3Bucket.Status.DeepCopy().Conditions
+		r.bucketQuota = r.s3Bucket.Status.Quota
+		r.bucketVersioning = r.s3Bucket.Status.Versioning
+		r.lifecycleApplied = r.s3Bucket.Status.LifecycleApplied
+		r.corsApplied = r.s3Bucket.Status.CORSApplied
+		r.bucketImport = r.s3Bucket.Status.Import
+		r.s3BucketName = r.s3Bucket.GetBucketName()
 		// Create a s3 session with the s3user credentials.
 		err = r.setS3Agent(ctx, req)
 		if err != nil {
 			r.logger.Error(err, "Failed to login on S3 with the user credentials")
+			r.setCondition(consts.ConditionTypeBucketSynced,
+				fmt.Errorf("failed to login on S3 with the user credentials, %w", err))
+			r.updateBucketStatus(ctx, r.s3Bucket.Status.Created, err.Error(), r.s3Bucket.Status.Policy)
 			return subreconciler.Evaluate(subreconciler.Requeue())
 		}
 		// Initialize ceph tenant and cephFullUserId variables
@@ -90,7 +137,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return r.Provision(ctx)
 }
 
-func (r *Reconciler) setS3Agent(ctx context.Context, req ctrl.Request) error {
+func (r *reconcileRequest) setS3Agent(ctx context.Context, req ctrl.Request) error {
 	// Set the s3Agent regarding the secret of the s3UserClaim
 	s3userclaim := &s3v1alpha1.S3UserClaim{}
 	s3userClaimNamespacedName := types.NamespacedName{Namespace: req.Namespace, Name: r.s3UserRef}
@@ -99,6 +146,16 @@ func (r *Reconciler) setS3Agent(ctx context.Context, req ctrl.Request) error {
 		return err
 	}
 
+	r.existingUser = s3userclaim.Spec.ExistingUser
+	r.s3UserClass, err = r.classResolver.Resolve(ctx, s3userclaim.Spec.S3UserClass)
+	if err != nil {
+		return err
+	}
+	r.rgwClient, err = r.s3UserClass.RgwClient()
+	if err != nil {
+		return err
+	}
+
 	userAdminSecret := &corev1.Secret{}
 	secretNamespacedName := types.NamespacedName{Namespace: req.NamespacedName.Namespace, Name: s3userclaim.Spec.AdminSecret}
 	err = r.Get(ctx, secretNamespacedName, userAdminSecret)
@@ -108,7 +165,7 @@ func (r *Reconciler) setS3Agent(ctx context.Context, req ctrl.Request) error {
 
 	accessKey := string(userAdminSecret.Data[consts.DataKeyAccessKey])
 	secretKey := string(userAdminSecret.Data[consts.DataKeySecretKey])
-	r.s3Agent, err = s3_agent.NewS3Agent(accessKey, secretKey, r.rgwEndpoint, true)
+	r.s3Agent, err = r.s3UserClass.NewS3Agent(accessKey, secretKey)
 	if err != nil {
 		return err
 	}
@@ -116,18 +173,8 @@ func (r *Reconciler) setS3Agent(ctx context.Context, req ctrl.Request) error {
 	return nil
 }
 
-func (r *Reconciler) initVars(req ctrl.Request) {
-	// TODO: This function is mutual with the s3userclaim handler. It should be moved to a higher layer.
-	// Only alphanumeric characters and underscore are allowed for tenant name
-	k8sNameSpecialChars := regexp.MustCompile(`[.-]`)
-	namespace := k8sNameSpecialChars.ReplaceAllString(req.Namespace, "_")
-	clusterName := k8sNameSpecialChars.ReplaceAllString(r.clusterName, "_")
-	r.cephTenant = fmt.Sprintf("%s__%s", clusterName, namespace)
-	r.cephUserFullId = fmt.Sprintf("%s$%s", r.cephTenant, r.s3UserRef)
-
-	r.subuserAccessMap = make(map[string]string)
-	for _, binding := range r.s3Bucket.Spec.S3SubuserBinding {
-		r.subuserAccessMap[binding.Name] = binding.Access
-	}
+func (r *reconcileRequest) initVars(req ctrl.Request) {
+	r.cephTenant, r.cephUserId = r.s3UserClass.CephUser(req.Namespace, r.s3UserRef, r.existingUser)
+	r.cephUserFullId = s3userclass.FullUserID(r.cephTenant, r.cephUserId)
 
 }
@@ -2,25 +2,42 @@ package s3bucket
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/ceph/go-ceph/rgw/admin"
 	"github.com/opdev/subreconciler"
+	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/s3_agent"
 	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
 )
 
 // Provision provisions the required resources for the s3UserClaim object
-func (r *Reconciler) Provision(ctx context.Context) (ctrl.Result, error) {
+func (r *reconcileRequest) Provision(ctx context.Context) (ctrl.Result, error) {
 	// Do the actual reconcile work
 	subrecs := []subreconciler.Fn{
+		r.importBucket,
+		r.generateBucketName,
+		// the finalizer is added before the bucket is created so that a bucket is never left behind if a later
+		// step fails and the S3Bucket is deleted
+		r.addCleanupFinalizer,
 		r.ensureBucket,
+		r.ensureBucketQuota,
+		r.ensureBucketVersioning,
+		r.ensureBucketLifecycle,
+		r.ensureBucketCORS,
 		r.ensureBucketPolicy,
 		r.updateBucketStatusSuccess,
-		r.addCleanupFinalizer,
 	}
 	for _, subrec := range subrecs {
 		result, err := subrec(ctx)
@@ -29,38 +46,254 @@ func (r *Reconciler) Provision(ctx context.Context) (ctrl.Result, error) {
 		}
 	}
 
+	// Reconcile again after a while to detect the changes made out of band
+	if r.resyncPeriod > 0 {
+		return subreconciler.Evaluate(subreconciler.RequeueWithDelay(r.resyncPeriod))
+	}
 	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 }
 
-func (r *Reconciler) ensureBucket(ctx context.Context) (*ctrl.Result, error) {
-	err := r.s3Agent.CreateBucket(r.s3Bucket.GetName())
+// generateBucketName generates the name of the bucket if it's to be generated. The name is persisted in an
+// annotation of the S3Bucket before the bucket is created so that a bucket is never created under two names, even if
+// the S3Bucket is restored without its status. The name of an S3Bucket generated before is moved from its status.
+func (r *reconcileRequest) generateBucketName(ctx context.Context) (*ctrl.Result, error) {
+	if r.s3Bucket.Spec.GenerateBucketName == nil || r.s3Bucket.Annotations[consts.AnnotationGeneratedBucketName] != "" {
+		return subreconciler.ContinueReconciling()
+	}
+	if r.s3BucketName == "" {
+		r.s3BucketName = r.s3Bucket.GenerateBucketName()
+		r.logger.Info("generated the bucket name", "bucketName", r.s3BucketName)
+	}
+
+	if r.s3Bucket.Annotations == nil {
+		r.s3Bucket.Annotations = map[string]string{}
+	}
+	r.s3Bucket.Annotations[consts.AnnotationGeneratedBucketName] = r.s3BucketName
+	if err := r.Update(ctx, r.s3Bucket); err != nil {
+		r.logger.Error(err, "failed to persist the generated bucket name")
+		return subreconciler.Requeue()
+	}
+	return r.updateBucketStatus(ctx, r.s3Bucket.Status.Created, r.s3Bucket.Status.Reason, r.s3Bucket.Status.Policy)
+}
+
+func (r *reconcileRequest) ensureBucket(ctx context.Context) (*ctrl.Result, error) {
+	err := r.s3Agent.CreateBucket(r.s3BucketName)
+	if err != nil {
+		r.logger.Error(err, "failed to create the bucket")
+		r.setCondition(consts.ConditionTypeBucketSynced, err)
+		r.updateBucketStatus(ctx, false, err.Error(), r.s3Bucket.Status.Policy)
+		return subreconciler.Requeue()
+	}
+	// Imported buckets already exist
+	if !r.s3Bucket.Status.Created && r.s3Bucket.Spec.ExistingBucket == "" {
+		r.recorder.Eventf(r.s3Bucket, corev1.EventTypeNormal, consts.EventReasonBucketCreated,
+			"Created bucket %s", r.s3BucketName)
+	}
+	r.setCondition(consts.ConditionTypeBucketSynced, nil)
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *reconcileRequest) ensureBucketQuota(ctx context.Context) (*ctrl.Result, error) {
+	// A bucket without quota is only limited by the quota of its user
+	desiredQuota := admin.QuotaSpec{
+		UID:        r.cephUserFullId,
+		Bucket:     r.s3BucketName,
+		QuotaType:  consts.QuotaTypeBucket,
+		Enabled:    pointer.Bool(false),
+		MaxSize:    pointer.Int64(-1),
+		MaxObjects: pointer.Int64(-1),
+	}
+	if quota := r.s3Bucket.Spec.Quota; quota != nil {
+		desiredQuota.Enabled = pointer.Bool(true)
+		if !quota.MaxSize.IsZero() {
+			desiredQuota.MaxSize = pointer.Int64(quota.MaxSize.Value())
+		}
+		if !quota.MaxObjects.IsZero() {
+			desiredQuota.MaxObjects = pointer.Int64(quota.MaxObjects.Value())
+		}
+	}
+
+	if err := r.rgwClient.SetIndividualBucketQuota(ctx, desiredQuota); err != nil {
+		r.logger.Error(err, "failed to set the bucket quota")
+		r.setCondition(consts.ConditionTypeBucketQuotaSynced, fmt.Errorf("failed to set the bucket quota, %w", err))
+		r.updateBucketStatus(ctx, true, err.Error(), r.s3Bucket.Status.Policy)
+		return subreconciler.Requeue()
+	}
+	r.bucketQuota = r.s3Bucket.Spec.Quota
+	r.setCondition(consts.ConditionTypeBucketQuotaSynced, nil)
+	return subreconciler.ContinueReconciling()
+}
+
+// ensureBucketVersioning brings the versioning of the bucket to the desired state. If the versioning isn't managed,
+// the observed state is only reported.
+func (r *reconcileRequest) ensureBucketVersioning(ctx context.Context) (*ctrl.Result, error) {
+	currentVersioning, err := r.s3Agent.GetBucketVersioning(r.s3BucketName)
 	if err != nil {
+		r.logger.Error(err, "failed to get the bucket versioning")
+		r.setCondition(consts.ConditionTypeBucketVersioningSynced,
+			fmt.Errorf("failed to get the bucket versioning, %w", err))
+		r.updateBucketStatus(ctx, true, err.Error(), r.s3Bucket.Status.Policy)
+		return subreconciler.Requeue()
+	}
+
+	desiredVersioning := r.s3Bucket.Spec.Versioning
+	if desiredVersioning != "" && desiredVersioning != currentVersioning {
+		if currentVersioning != "" {
+			r.logger.Info("bucket versioning has drifted", "desired", desiredVersioning, "current", currentVersioning)
+		}
+		if err := r.s3Agent.SetBucketVersioning(r.s3BucketName, desiredVersioning); err != nil {
+			r.logger.Error(err, "failed to set the bucket versioning")
+			r.setCondition(consts.ConditionTypeBucketVersioningSynced,
+				fmt.Errorf("failed to set the bucket versioning, %w", err))
+			r.updateBucketStatus(ctx, true, err.Error(), r.s3Bucket.Status.Policy)
+			return subreconciler.Requeue()
+		}
+		currentVersioning = desiredVersioning
+	}
+
+	r.bucketVersioning = currentVersioning
+	r.setCondition(consts.ConditionTypeBucketVersioningSynced, nil)
+	return subreconciler.ContinueReconciling()
+}
+
+// ensureBucketLifecycle applies the lifecycle configuration of the bucket. Without rules, only a configuration which
+// the operator applied is removed, so a configuration set out of band is left untouched.
+func (r *reconcileRequest) ensureBucketLifecycle(ctx context.Context) (*ctrl.Result, error) {
+	lifecycle := r.s3Bucket.Spec.Lifecycle
+	hasRules := lifecycle != nil && len(lifecycle.Rules) > 0
+	if !hasRules && !r.lifecycleApplied {
+		r.setCondition(consts.ConditionTypeBucketLifecycleSynced, nil)
+		return subreconciler.ContinueReconciling()
+	}
+	if err := r.s3Agent.SetBucketLifecycle(r.s3BucketName, lifecycle); err != nil {
+		r.logger.Error(err, "failed to set the bucket lifecycle")
+		r.setCondition(consts.ConditionTypeBucketLifecycleSynced,
+			fmt.Errorf("failed to set the bucket lifecycle, %w", err))
+		r.updateBucketStatus(ctx, true, err.Error(), r.s3Bucket.Status.Policy)
+		return subreconciler.Requeue()
+	}
+	r.lifecycleApplied = hasRules
+	r.setCondition(consts.ConditionTypeBucketLifecycleSynced, nil)
+	return subreconciler.ContinueReconciling()
+}
+
+// ensureBucketCORS applies the CORS configuration of the bucket. Without rules, only a configuration which the operator
+// applied is removed, so a configuration set out of band is left untouched.
+func (r *reconcileRequest) ensureBucketCORS(ctx context.Context) (*ctrl.Result, error) {
+	cors := r.s3Bucket.Spec.CORS
+	hasRules := cors != nil && len(cors.Rules) > 0
+	if !hasRules && !r.corsApplied {
+		r.setCondition(consts.ConditionTypeBucketCORSSynced, nil)
+		return subreconciler.ContinueReconciling()
+	}
+	if err := r.s3Agent.SetBucketCORS(r.s3BucketName, cors); err != nil {
+		r.logger.Error(err, "failed to set the bucket CORS")
+		r.setCondition(consts.ConditionTypeBucketCORSSynced, fmt.Errorf("failed to set the bucket CORS, %w", err))
+		r.updateBucketStatus(ctx, true, err.Error(), r.s3Bucket.Status.Policy)
 		return subreconciler.Requeue()
 	}
+	r.corsApplied = hasRules
+	r.setCondition(consts.ConditionTypeBucketCORSSynced, nil)
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *Reconciler) ensureBucketPolicy(ctx context.Context) (*ctrl.Result, error) {
-	var err error
-	r.bucketPolicy, err = r.s3Agent.SetBucketPolicy(r.subuserAccessMap,
-		r.cephTenant, r.s3UserRef, r.s3BucketName)
+func (r *reconcileRequest) ensureBucketPolicy(ctx context.Context) (*ctrl.Result, error) {
+	grants, err := r.acceptedGrants(ctx)
+	if err != nil {
+		r.logger.Error(err, "failed to list the grants of the bucket")
+		r.setCondition(consts.ConditionTypeBucketPolicySynced,
+			fmt.Errorf("failed to list the grants of the bucket, %w", err))
+		r.updateBucketStatus(ctx, true, err.Error(), r.bucketPolicy)
+		return subreconciler.Requeue()
+	}
+	// The public access and the additional statements which allow anonymous users are left out of the policy once the
+	// class doesn't allow the public access for the namespace anymore
+	publicAccess := r.s3Bucket.Spec.PublicAccess
+	additionalStatements := r.s3Bucket.Spec.AdditionalPolicyStatements
+	publicStatements := r.s3Bucket.PublicPolicyStatements()
+	publicAccessAllowed := (!publicAccess.IsEnabled() && len(publicStatements) == 0) ||
+		r.s3UserClass.PublicAccess.Allows(r.s3Bucket.Namespace)
+	if !publicAccessAllowed {
+		publicAccess = nil
+		additionalStatements = withoutStatements(additionalStatements, publicStatements)
+	}
+	r.bucketPolicy, err = r.s3Agent.SetBucketPolicy(r.s3Bucket.Spec.S3SubuserBinding, grants, publicAccess,
+		additionalStatements, r.cephTenant, r.cephUserId, r.s3BucketName)
 	if err != nil {
 		r.logger.Error(err, "failed to set the bucket policy")
+		r.setCondition(consts.ConditionTypeBucketPolicySynced, err)
+		r.updateBucketStatus(ctx, true, err.Error(), r.bucketPolicy)
+		return subreconciler.Requeue()
+	}
+	if r.bucketPolicy != r.s3Bucket.Status.Policy {
+		r.recorder.Event(r.s3Bucket, corev1.EventTypeNormal, consts.EventReasonPolicyApplied,
+			"Applied the bucket policy")
+	}
+	if !publicAccessAllowed {
+		err = fmt.Errorf("%w, namespace=%s", consts.ErrPublicAccessNotAllowed, r.s3Bucket.Namespace)
+		r.logger.Error(err, "failed to set the public access of the bucket")
+		r.setCondition(consts.ConditionTypeBucketPolicySynced, err)
 		r.updateBucketStatus(ctx, true, err.Error(), r.bucketPolicy)
 		return subreconciler.Requeue()
 	}
+	r.setCondition(consts.ConditionTypeBucketPolicySynced, nil)
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *Reconciler) updateBucketStatusSuccess(ctx context.Context) (*ctrl.Result, error) {
+// withoutStatements returns the statements except the ones at the given indices
+func withoutStatements(statements []runtime.RawExtension, indices []int) []runtime.RawExtension {
+	excluded := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		excluded[i] = true
+	}
+	var kept []runtime.RawExtension
+	for i, statement := range statements {
+		if !excluded[i] {
+			kept = append(kept, statement)
+		}
+	}
+	return kept
+}
+
+// acceptedGrants returns the grants of the bucket to other namespaces which are accepted by their grantees
+func (r *reconcileRequest) acceptedGrants(ctx context.Context) ([]s3_agent.BucketGrant, error) {
+	s3BucketAccessList := &s3v1alpha1.S3BucketAccessList{}
+	if err := r.List(ctx, s3BucketAccessList, client.InNamespace(r.s3Bucket.Namespace)); err != nil {
+		return nil, err
+	}
+	var grants []s3_agent.BucketGrant
+	for _, s3BucketAccess := range s3BucketAccessList.Items {
+		grant := s3BucketAccess.Spec.Grant
+		if grant == nil || grant.S3BucketRef != r.s3Bucket.Name || s3BucketAccess.Status.Principal == "" {
+			continue
+		}
+		grants = append(grants, s3_agent.BucketGrant{
+			Principal: s3BucketAccess.Status.Principal,
+			Access:    grant.Access,
+			Prefixes:  grant.Prefixes,
+		})
+	}
+	return grants, nil
+}
+
+func (r *reconcileRequest) updateBucketStatusSuccess(ctx context.Context) (*ctrl.Result, error) {
+	r.setReadyCondition(metav1.ConditionTrue, consts.ConditionReasonProvisioned, "")
 	return r.updateBucketStatus(ctx, true, "", r.bucketPolicy)
 }
-func (r *Reconciler) updateBucketStatus(ctx context.Context,
+func (r *reconcileRequest) updateBucketStatus(ctx context.Context,
 	created bool, reason string, policy string) (*ctrl.Result, error) {
 	status := s3v1alpha1.S3BucketStatus{
-		Created: created,
-		Reason:  reason,
-		Policy:  policy,
+		Created:            created,
+		Reason:             reason,
+		Policy:             policy,
+		BucketName:         r.s3BucketName,
+		Quota:              r.bucketQuota,
+		Versioning:         r.bucketVersioning,
+		LifecycleApplied:   r.lifecycleApplied,
+		CORSApplied:        r.corsApplied,
+		Import:             r.bucketImport,
+		ObservedGeneration: r.s3Bucket.Generation,
+		Conditions:         r.conditions,
 	}
 
 	if !apiequality.Semantic.DeepEqual(r.s3Bucket.Status, status) {
@@ -75,7 +308,38 @@ func (r *Reconciler) updateBucketStatus(ctx context.Context,
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *Reconciler) addCleanupFinalizer(ctx context.Context) (*ctrl.Result, error) {
+// setCondition sets the condition of a provisioning step regarding its error.
+// A failed step marks the S3Bucket as not ready as well.
+func (r *reconcileRequest) setCondition(conditionType string, err error) {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             consts.ConditionReasonSynced,
+		ObservedGeneration: r.s3Bucket.Generation,
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = consts.ConditionReasonSyncFailed
+		condition.Message = err.Error()
+		r.setReadyCondition(metav1.ConditionFalse, consts.ConditionReasonProvisioningFailed,
+			fmt.Sprintf("%s: %s", conditionType, err.Error()))
+		r.recorder.Eventf(r.s3Bucket, corev1.EventTypeWarning, consts.EventReasonSyncFailed, "%s: %s",
+			conditionType, err.Error())
+	}
+	meta.SetStatusCondition(&r.conditions, condition)
+}
+
+func (r *reconcileRequest) setReadyCondition(status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&r.conditions, metav1.Condition{
+		Type:               consts.ConditionTypeReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: r.s3Bucket.Generation,
+	})
+}
+
+func (r *reconcileRequest) addCleanupFinalizer(ctx context.Context) (*ctrl.Result, error) {
 	if objUpdated := controllerutil.AddFinalizer(r.s3Bucket, consts.S3BucketCleanupFinalizer); objUpdated {
 		if err := r.Update(ctx, r.s3Bucket); err != nil {
 			r.logger.Error(err, "failed to add finalizer to the s3Bucket")
@@ -0,0 +1,142 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3bucket
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/config"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/s3_agent"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+var _ = Describe("S3Bucket Controller", func() {
+	const (
+		// The namespace keeps the Ceph users of this suite apart from the ones of the s3UserClaim suite
+		namespace          = "s3bucket-controller-test"
+		s3UserClaimName    = "test-s3userclaim"
+		s3BucketName       = "test-s3bucket"
+		adminSecretName    = "admin-secret"
+		readonlySecretName = "readonly-secret"
+	)
+	var (
+		cfg         = config.DefaultConfig
+		ctx         = context.Background()
+		s3UserClaim *s3v1alpha1.S3UserClaim
+		s3Bucket    *s3v1alpha1.S3Bucket
+	)
+
+	BeforeEach(func() {
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		}))).To(Succeed())
+
+		s3UserClaim = &s3v1alpha1.S3UserClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s3UserClaimName,
+				Namespace: namespace,
+			},
+			Spec: s3v1alpha1.S3UserClaimSpec{
+				ReadonlySecret: readonlySecretName,
+				AdminSecret:    adminSecretName,
+				Quota: &s3v1alpha1.UserQuota{
+					MaxSize:    resource.MustParse("1M"),
+					MaxObjects: resource.MustParse("1k"),
+					MaxBuckets: 5,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(s3UserClaim), s3UserClaim)).To(Succeed())
+			g.Expect(meta.IsStatusConditionTrue(s3UserClaim.Status.Conditions, consts.ConditionTypeReady)).To(BeTrue())
+		}).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		By("Expect to delete the S3Bucket and the S3UserClaim successfully")
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, s3Bucket))).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(apierrors.IsNotFound(
+				k8sClient.Get(ctx, client.ObjectKeyFromObject(s3Bucket), &s3v1alpha1.S3Bucket{}),
+			)).To(BeTrue())
+		}).Should(Succeed())
+		Expect(k8sClient.Delete(ctx, s3UserClaim)).To(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(apierrors.IsNotFound(
+				k8sClient.Get(ctx, client.ObjectKeyFromObject(s3UserClaim), &s3v1alpha1.S3UserClaim{}),
+			)).To(BeTrue())
+		}).Should(Succeed())
+
+		// The secrets aren't garbage collected by envtest
+		for _, secretName := range []string{adminSecretName, readonlySecretName} {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+			}))).To(Succeed())
+		}
+	})
+
+	Context("When the versioning of a bucket is changed out of band", func() {
+		It("Should restore the versioning of the S3Bucket", func() {
+			s3Bucket = &s3v1alpha1.S3Bucket{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s3BucketName,
+					Namespace: namespace,
+				},
+				Spec: s3v1alpha1.S3BucketSpec{
+					S3UserRef:        s3UserClaimName,
+					S3DeletionPolicy: consts.DeletionPolicyDelete,
+					Versioning:       "Enabled",
+				},
+			}
+			Expect(k8sClient.Create(ctx, s3Bucket)).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(s3Bucket), s3Bucket)).To(Succeed())
+				g.Expect(s3Bucket.Status.Created).To(BeTrue())
+				g.Expect(s3Bucket.Status.Versioning).To(Equal("Enabled"))
+			}).Should(Succeed())
+
+			By("Expect to suspend the versioning with the keys of the user")
+			adminSecret := &v1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: adminSecretName},
+				adminSecret)).To(Succeed())
+			s3Agent, err := s3_agent.NewS3Agent(string(adminSecret.Data[consts.DataKeyAccessKey]),
+				string(adminSecret.Data[consts.DataKeySecretKey]), cfg.Rgw.Endpoint, cfg.Rgw.Region, nil,
+				cfg.Rgw.MaxRetries, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s3Agent.SetBucketVersioning(s3Bucket.GetBucketName(), "Suspended")).To(Succeed())
+
+			By("Expect the resync to enable the versioning again")
+			Eventually(func(g Gomega) {
+				versioning, err := s3Agent.GetBucketVersioning(s3Bucket.GetBucketName())
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(versioning).To(Equal("Enabled"))
+			}).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,116 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3bucketaccess
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+)
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&s3v1alpha1.S3BucketAccess{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// A grant and its acceptance track each other
+		Watches(
+			&source.Kind{Type: &s3v1alpha1.S3BucketAccess{}},
+			handler.EnqueueRequestsFromMapFunc(r.s3BucketAccessToCounterparts)).
+		Watches(
+			&source.Kind{Type: &s3v1alpha1.S3Bucket{}},
+			handler.EnqueueRequestsFromMapFunc(r.s3BucketToGrants)).
+		Watches(
+			&source.Kind{Type: &s3v1alpha1.S3UserClaim{}},
+			handler.EnqueueRequestsFromMapFunc(r.s3UserClaimToGrants)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.maxConcurrentReconciles}).
+		Complete(r)
+}
+
+// s3BucketAccessToCounterparts enqueues the grant of an acceptance, or the acceptances of a grant
+func (r *Reconciler) s3BucketAccessToCounterparts(object client.Object) []reconcile.Request {
+	s3BucketAccess, ok := object.(*s3v1alpha1.S3BucketAccess)
+	if !ok {
+		return nil
+	}
+	if accept := s3BucketAccess.Spec.Accept; accept != nil {
+		return []reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: accept.Namespace, Name: accept.Name}},
+		}
+	}
+	if s3BucketAccess.Spec.Grant == nil {
+		return nil
+	}
+
+	s3BucketAccessList := &s3v1alpha1.S3BucketAccessList{}
+	if err := r.List(context.Background(), s3BucketAccessList,
+		client.InNamespace(s3BucketAccess.Spec.Grant.Grantee.Namespace)); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, acceptance := range s3BucketAccessList.Items {
+		if acceptance.Accepts(s3BucketAccess) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: acceptance.Namespace, Name: acceptance.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// s3BucketToGrants enqueues the grants on the s3Bucket, so that they track the name of its bucket
+func (r *Reconciler) s3BucketToGrants(object client.Object) []reconcile.Request {
+	return r.grantsMatching(func(s3BucketAccess *s3v1alpha1.S3BucketAccess) bool {
+		return s3BucketAccess.Namespace == object.GetNamespace() &&
+			s3BucketAccess.Spec.Grant.S3BucketRef == object.GetName()
+	})
+}
+
+// s3UserClaimToGrants enqueues the grants to the s3UserClaim, so that they track the Ceph user of the claim
+func (r *Reconciler) s3UserClaimToGrants(object client.Object) []reconcile.Request {
+	return r.grantsMatching(func(s3BucketAccess *s3v1alpha1.S3BucketAccess) bool {
+		grantee := s3BucketAccess.Spec.Grant.Grantee
+		return grantee.Namespace == object.GetNamespace() && grantee.S3UserClaim == object.GetName()
+	})
+}
+
+func (r *Reconciler) grantsMatching(matches func(*s3v1alpha1.S3BucketAccess) bool) []reconcile.Request {
+	s3BucketAccessList := &s3v1alpha1.S3BucketAccessList{}
+	if err := r.List(context.Background(), s3BucketAccessList); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range s3BucketAccessList.Items {
+		s3BucketAccess := &s3BucketAccessList.Items[i]
+		if s3BucketAccess.Spec.Grant != nil && matches(s3BucketAccess) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: s3BucketAccess.Namespace, Name: s3BucketAccess.Name},
+			})
+		}
+	}
+	return requests
+}
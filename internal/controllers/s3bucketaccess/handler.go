@@ -0,0 +1,253 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3bucketaccess
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/opdev/subreconciler"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/config"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/s3_agent"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/s3userclass"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// Reconciler reconciles a S3BucketAccess object
+type Reconciler struct {
+	client.Client
+	scheme        *runtime.Scheme
+	classResolver *s3userclass.Resolver
+
+	// configurations
+	maxConcurrentReconciles int
+}
+
+// reconcileRequest holds the state of a single reconciliation. A new one is created on each call to Reconcile
+// so that concurrent reconciles never share any mutable state.
+type reconcileRequest struct {
+	*Reconciler
+	logger logr.Logger
+
+	s3BucketAccess *s3v1alpha1.S3BucketAccess
+	status         s3v1alpha1.S3BucketAccessStatus
+}
+
+func NewReconciler(mgr manager.Manager, cfg *config.Config) *Reconciler {
+	return &Reconciler{
+		Client:        mgr.GetClient(),
+		scheme:        mgr.GetScheme(),
+		classResolver: s3userclass.NewResolver(mgr.GetClient(), cfg),
+
+		maxConcurrentReconciles: cfg.Controllers.S3BucketAccess.MaxConcurrentReconciles,
+	}
+}
+
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3bucketaccesses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3bucketaccesses/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3buckets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3userclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3userclasses,verbs=get;list;watch
+
+// Reconcile records whether a grant is accepted, and the principal of its grantee once it's accepted. The S3Bucket
+// controller renders the principals of the accepted grants in the bucket policy.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	rr := &reconcileRequest{
+		Reconciler:     r,
+		logger:         log.FromContext(ctx),
+		s3BucketAccess: &s3v1alpha1.S3BucketAccess{},
+	}
+	return rr.reconcile(ctx, req)
+}
+
+func (r *reconcileRequest) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	switch err := r.Get(ctx, req.NamespacedName, r.s3BucketAccess); {
+	case apierrors.IsNotFound(err):
+		return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+	case err != nil:
+		r.logger.Error(err, "failed to fetch object")
+		return subreconciler.Evaluate(subreconciler.Requeue())
+	}
+	r.status.Conditions = r.s3BucketAccess.Status.DeepCopy().Conditions
+
+	if r.s3BucketAccess.Spec.Accept != nil {
+		return subreconciler.Evaluate(r.reconcileAcceptance(ctx))
+	}
+	return subreconciler.Evaluate(r.reconcileGrant(ctx))
+}
+
+// reconcileAcceptance mirrors the state of the accepted grant
+func (r *reconcileRequest) reconcileAcceptance(ctx context.Context) (*ctrl.Result, error) {
+	accept := r.s3BucketAccess.Spec.Accept
+	grant := &s3v1alpha1.S3BucketAccess{}
+	switch err := r.Get(ctx, types.NamespacedName{Namespace: accept.Namespace, Name: accept.Name}, grant); {
+	case apierrors.IsNotFound(err):
+		r.setReadyCondition(metav1.ConditionFalse, consts.ConditionReasonSyncFailed, consts.ErrGrantNotFound.Error())
+		return r.updateStatus(ctx)
+	case err != nil:
+		r.logger.Error(err, "failed to get the accepted grant")
+		return subreconciler.Requeue()
+	}
+	if !r.s3BucketAccess.Accepts(grant) {
+		r.setReadyCondition(metav1.ConditionFalse, consts.ConditionReasonSyncFailed, consts.ErrGrantNotFound.Error())
+		return r.updateStatus(ctx)
+	}
+
+	r.status.Bucket = grant.Status.Bucket
+	r.status.Principal = grant.Status.Principal
+	r.setReadyCondition(metav1.ConditionTrue, consts.ConditionReasonAccepted, "")
+	return r.updateStatus(ctx)
+}
+
+// reconcileGrant resolves the bucket of the grant and, once the grant is accepted, the principal of its grantee
+func (r *reconcileRequest) reconcileGrant(ctx context.Context) (*ctrl.Result, error) {
+	grant := r.s3BucketAccess.Spec.Grant
+	s3Bucket := &s3v1alpha1.S3Bucket{}
+	switch err := r.Get(ctx, types.NamespacedName{Namespace: r.s3BucketAccess.Namespace, Name: grant.S3BucketRef},
+		s3Bucket); {
+	case apierrors.IsNotFound(err):
+		r.setReadyCondition(metav1.ConditionFalse, consts.ConditionReasonSyncFailed, consts.ErrS3BucketNotFound.Error())
+		return r.updateStatus(ctx)
+	case err != nil:
+		r.logger.Error(err, "failed to get s3Bucket")
+		return subreconciler.Requeue()
+	}
+	r.status.Bucket = s3Bucket.GetBucketName()
+
+	accepted, err := r.isAccepted(ctx)
+	if err != nil {
+		r.logger.Error(err, "failed to find the acceptance of the grant")
+		return subreconciler.Requeue()
+	}
+	if !accepted {
+		r.setReadyCondition(metav1.ConditionFalse, consts.ConditionReasonPendingAcceptance,
+			fmt.Sprintf("waiting for namespace %s to accept the grant", grant.Grantee.Namespace))
+		return r.updateStatus(ctx)
+	}
+
+	principal, err := r.granteePrincipal(ctx, s3Bucket)
+	switch {
+	case err == consts.ErrGranteeNotFound || err == consts.ErrGranteeSubuserNotFound ||
+		err == consts.ErrGranteeClassMismatch:
+		r.setReadyCondition(metav1.ConditionFalse, consts.ConditionReasonSyncFailed, err.Error())
+		return r.updateStatus(ctx)
+	case err != nil:
+		r.logger.Error(err, "failed to resolve the principal of the grantee")
+		r.setReadyCondition(metav1.ConditionFalse, consts.ConditionReasonSyncFailed,
+			fmt.Sprintf("failed to resolve the principal of the grantee, %s", err))
+		if result, err := r.updateStatus(ctx); subreconciler.ShouldHaltOrRequeue(result, err) {
+			return result, err
+		}
+		return subreconciler.Requeue()
+	}
+
+	r.status.Principal = principal
+	r.setReadyCondition(metav1.ConditionTrue, consts.ConditionReasonAccepted, "")
+	return r.updateStatus(ctx)
+}
+
+// isAccepted reports whether an S3BucketAccess of the grantee namespace accepts the grant
+func (r *reconcileRequest) isAccepted(ctx context.Context) (bool, error) {
+	s3BucketAccessList := &s3v1alpha1.S3BucketAccessList{}
+	if err := r.List(ctx, s3BucketAccessList,
+		client.InNamespace(r.s3BucketAccess.Spec.Grant.Grantee.Namespace)); err != nil {
+		return false, err
+	}
+	for _, s3BucketAccess := range s3BucketAccessList.Items {
+		if s3BucketAccess.DeletionTimestamp == nil && s3BucketAccess.Accepts(r.s3BucketAccess) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// granteePrincipal returns the principal of the grantee in the bucket policy. The grantee must be on the same Ceph
+// cluster as the bucket.
+func (r *reconcileRequest) granteePrincipal(ctx context.Context, s3Bucket *s3v1alpha1.S3Bucket) (string, error) {
+	grantee := r.s3BucketAccess.Spec.Grant.Grantee
+	granteeClaim := &s3v1alpha1.S3UserClaim{}
+	switch err := r.Get(ctx, types.NamespacedName{Namespace: grantee.Namespace, Name: grantee.S3UserClaim},
+		granteeClaim); {
+	case apierrors.IsNotFound(err):
+		return "", consts.ErrGranteeNotFound
+	case err != nil:
+		return "", fmt.Errorf("failed to get the grantee s3UserClaim, %w", err)
+	}
+	if grantee.Subuser != "" && !hasSubuser(granteeClaim, grantee.Subuser) {
+		return "", consts.ErrGranteeSubuserNotFound
+	}
+
+	ownerClaim := &s3v1alpha1.S3UserClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: s3Bucket.Namespace, Name: s3Bucket.Spec.S3UserRef},
+		ownerClaim); err != nil {
+		return "", fmt.Errorf("failed to get the s3UserClaim of the bucket, %w", err)
+	}
+	if r.classResolver.Name(ownerClaim.Spec.S3UserClass) != r.classResolver.Name(granteeClaim.Spec.S3UserClass) {
+		return "", consts.ErrGranteeClassMismatch
+	}
+
+	s3UserClass, err := r.classResolver.Resolve(ctx, granteeClaim.Spec.S3UserClass)
+	if err != nil {
+		return "", err
+	}
+	tenant, userId := s3UserClass.CephUser(granteeClaim.Namespace, granteeClaim.Name, granteeClaim.Spec.ExistingUser)
+	return s3_agent.UserPrincipal(tenant, userId, grantee.Subuser), nil
+}
+
+func (r *reconcileRequest) setReadyCondition(status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&r.status.Conditions, metav1.Condition{
+		Type:               consts.ConditionTypeReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: r.s3BucketAccess.Generation,
+	})
+}
+
+func (r *reconcileRequest) updateStatus(ctx context.Context) (*ctrl.Result, error) {
+	if apiequality.Semantic.DeepEqual(r.s3BucketAccess.Status, r.status) {
+		return subreconciler.DoNotRequeue()
+	}
+	r.s3BucketAccess.Status = r.status
+	if err := r.Status().Update(ctx, r.s3BucketAccess); err != nil {
+		r.logger.Error(err, "failed to update s3BucketAccess status")
+		return subreconciler.Requeue()
+	}
+	return subreconciler.DoNotRequeue()
+}
+
+func hasSubuser(s3UserClaim *s3v1alpha1.S3UserClaim, subuser string) bool {
+	for _, claimSubuser := range s3UserClaim.Spec.Subusers {
+		if string(claimSubuser) == subuser {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package s3userclaim
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/opdev/subreconciler"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// verifyAdoption makes sure the existing Ceph user which the claim adopts can be bound to the claim. The user must
+// exist, must be in a tenant which the namespace can adopt from, must not be an administrative user and must not be
+// bound to another S3User. A retained S3User of the user is
+// released so that the user is bound to the claim from now on.
+func (r *reconcileRequest) verifyAdoption(ctx context.Context) (*ctrl.Result, error) {
+	if r.s3UserClaim.Spec.ExistingUser == "" {
+		return subreconciler.ContinueReconciling()
+	}
+	logger := r.logger.WithValues("userId", r.cephUserFullId)
+
+	existingUser, err := r.rgwClient.GetUser(ctx, admin.User{ID: r.cephUserFullId})
+	switch {
+	case goerrors.Is(err, admin.ErrNoSuchUser):
+		logger.Error(err, "failed to find the ceph user to adopt")
+		r.setCondition(consts.ConditionTypeCephUserSynced, consts.ErrCephUserNotFound)
+		return subreconciler.Requeue()
+	case err != nil:
+		logger.Error(err, "failed to get ceph user")
+		r.setCondition(consts.ConditionTypeCephUserSynced, fmt.Errorf("failed to get ceph user, %w", err))
+		return subreconciler.Requeue()
+	}
+
+	if err := r.checkAdoptable(ctx, existingUser); err != nil {
+		logger.Error(err, "refused to adopt ceph user")
+		r.setCondition(consts.ConditionTypeCephUserSynced, err)
+		return subreconciler.Requeue()
+	}
+
+	boundS3User, err := r.findBoundS3User(ctx)
+	if err != nil {
+		logger.Error(err, "failed to find the s3User bound to the ceph user")
+		r.setCondition(consts.ConditionTypeCephUserSynced,
+			fmt.Errorf("failed to find the s3User bound to the ceph user, %w", err))
+		return subreconciler.Requeue()
+	}
+	if boundS3User != nil {
+		if boundS3User.Status.RetainedTime == nil {
+			logger.Info("refused to adopt ceph user", "s3User", boundS3User.Name)
+			r.setCondition(consts.ConditionTypeCephUserSynced,
+				fmt.Errorf("%w: %s", consts.ErrCephUserAlreadyBound, boundS3User.Name))
+			return subreconciler.Requeue()
+		}
+		// The cleanup of a claim without S3User never touches the Ceph user, so releasing the S3User is safe. The
+		// preconditions keep the S3User if another claim has bound the user in the meantime.
+		logger.Info("releasing the retained s3User of the ceph user", "s3User", boundS3User.Name)
+		preconditions := client.Preconditions{UID: &boundS3User.UID, ResourceVersion: &boundS3User.ResourceVersion}
+		if err := r.Delete(ctx, boundS3User, preconditions); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to release the retained s3User")
+			r.setCondition(consts.ConditionTypeCephUserSynced,
+				fmt.Errorf("failed to release the retained s3User, %w", err))
+			return subreconciler.Requeue()
+		}
+	}
+
+	// An adopted user keeps its display name
+	r.cephDisplayName = existingUser.DisplayName
+	return subreconciler.ContinueReconciling()
+}
+
+// bindAdoptedUser binds the existing Ceph user to the claim by creating its S3User before the user is changed. The
+// S3User is named after the user, so when several claims adopt the user at once, only one of them creates it and the
+// others are refused.
+func (r *reconcileRequest) bindAdoptedUser(ctx context.Context) (*ctrl.Result, error) {
+	if r.s3UserClaim.Spec.ExistingUser == "" {
+		return subreconciler.ContinueReconciling()
+	}
+	logger := r.logger.WithValues("userId", r.cephUserFullId, "s3User", r.s3UserName)
+
+	s3User, err := r.assembleS3User()
+	if err != nil {
+		logger.Error(err, "failed to assemble s3 user")
+		r.setCondition(consts.ConditionTypeCephUserSynced, fmt.Errorf("failed to assemble s3 user, %w", err))
+		return subreconciler.Requeue()
+	}
+	switch err := r.Create(ctx, s3User); {
+	case err == nil:
+		return subreconciler.ContinueReconciling()
+	case !apierrors.IsAlreadyExists(err):
+		logger.Error(err, "failed to create the s3User of the ceph user")
+		r.setCondition(consts.ConditionTypeCephUserSynced,
+			fmt.Errorf("failed to create the s3User of the ceph user, %w", err))
+		return subreconciler.Requeue()
+	}
+
+	if err := r.uncachedReader.Get(ctx, types.NamespacedName{Name: r.s3UserName}, s3User); err != nil {
+		logger.Error(err, "failed to get the s3User of the ceph user")
+		r.setCondition(consts.ConditionTypeCephUserSynced,
+			fmt.Errorf("failed to get the s3User of the ceph user, %w", err))
+		return subreconciler.Requeue()
+	}
+	if !isBoundToClaim(s3User, r.s3UserClaimNamespace, r.s3UserClaimName) {
+		logger.Info("refused to adopt ceph user which is bound to another claim")
+		r.setCondition(consts.ConditionTypeCephUserSynced,
+			fmt.Errorf("%w: %s", consts.ErrCephUserAlreadyBound, s3User.Name))
+		return subreconciler.Requeue()
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// checkAdoptable refuses the users of the tenants which the namespace isn't allowed to adopt from and the users which
+// have administrative capabilities, including the admin user of the class. Until the user is bound to the claim, it's
+// also refused while it has subusers which aren't in the claim, since they'd be removed by the claim.
+func (r *reconcileRequest) checkAdoptable(ctx context.Context, user admin.User) error {
+	if !r.s3UserClass.AllowsAdoption(r.s3UserClaimNamespace, r.cephTenant) {
+		return fmt.Errorf("%w: the user isn't in the tenant of the namespace", consts.ErrCephUserNotAdoptable)
+	}
+	if len(user.Caps) > 0 {
+		return fmt.Errorf("%w: the user has administrative capabilities", consts.ErrCephUserNotAdoptable)
+	}
+	for _, key := range user.Keys {
+		if key.AccessKey == r.s3UserClass.AccessKey {
+			return fmt.Errorf("%w: the user is the admin user of the s3UserClass", consts.ErrCephUserNotAdoptable)
+		}
+	}
+
+	adopted, err := r.isAdopted(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get s3User, %w", err)
+	}
+	if adopted {
+		return nil
+	}
+	desiredSubusers := map[string]bool{r.readonlyCephUserFullId: true}
+	for _, subuser := range retrieveSubusersString(r.s3UserClaim.Spec.Subusers) {
+		desiredSubusers[generateSubuserFullId(r.cephUserFullId, subuser)] = true
+	}
+	var unknownSubusers []string
+	for _, subuser := range user.Subusers {
+		if !desiredSubusers[subuser.Name] {
+			unknownSubusers = append(unknownSubusers, subuser.Name)
+		}
+	}
+	if len(unknownSubusers) > 0 {
+		return fmt.Errorf("%w: the subusers %s of the user aren't in the subusers of the claim",
+			consts.ErrCephUserNotAdoptable, strings.Join(unknownSubusers, ", "))
+	}
+	return nil
+}
+
+// isAdopted reports whether the S3User of the claim is already bound to the Ceph user
+func (r *reconcileRequest) isAdopted(ctx context.Context) (bool, error) {
+	s3User := &s3v1alpha1.S3User{}
+	switch err := r.uncachedReader.Get(ctx, types.NamespacedName{Name: r.s3UserName}, s3User); {
+	case apierrors.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	return isBoundToClaim(s3User, r.s3UserClaimNamespace, r.s3UserClaimName) &&
+		s3User.Spec.CephUserID == r.cephUserFullId, nil
+}
+
+// findBoundS3User returns the S3User of another claim which is bound to the Ceph user of the claim, if any
+func (r *reconcileRequest) findBoundS3User(ctx context.Context) (*s3v1alpha1.S3User, error) {
+	s3UserList := &s3v1alpha1.S3UserList{}
+	if err := r.uncachedReader.List(ctx, s3UserList); err != nil {
+		return nil, err
+	}
+
+	for i := range s3UserList.Items {
+		s3User := &s3UserList.Items[i]
+		if isBoundToClaim(s3User, r.s3UserClaimNamespace, r.s3UserClaimName) ||
+			r.classResolver.Name(s3User.Spec.S3UserClass) != r.s3UserClass.Name {
+			continue
+		}
+		if r.s3UserClass.S3UserCephUserID(s3User) == r.cephUserFullId {
+			return s3User, nil
+		}
+	}
+	return nil, nil
+}
+
+// findCephUserHolder returns the name of the S3User of another claim which is bound to the Ceph user of the claim, or
+// the namespaced name of another claim which adopts the user, if any
+func (r *reconcileRequest) findCephUserHolder(ctx context.Context) (string, error) {
+	boundS3User, err := r.findBoundS3User(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the s3User bound to the ceph user, %w", err)
+	}
+	if boundS3User != nil {
+		return boundS3User.Name, nil
+	}
+
+	s3UserClaimList := &s3v1alpha1.S3UserClaimList{}
+	if err := r.uncachedReader.List(ctx, s3UserClaimList); err != nil {
+		return "", fmt.Errorf("failed to list s3UserClaims, %w", err)
+	}
+	for _, s3UserClaim := range s3UserClaimList.Items {
+		isClaim := s3UserClaim.Namespace == r.s3UserClaimNamespace && s3UserClaim.Name == r.s3UserClaimName
+		if !isClaim && s3UserClaim.Spec.ExistingUser == r.cephUserFullId &&
+			r.classResolver.Name(s3UserClaim.Spec.S3UserClass) == r.s3UserClass.Name {
+			return fmt.Sprintf("%s/%s", s3UserClaim.Namespace, s3UserClaim.Name), nil
+		}
+	}
+	return "", nil
+}
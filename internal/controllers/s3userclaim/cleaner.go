@@ -3,11 +3,15 @@ package s3userclaim
 import (
 	"context"
 	goerrors "errors"
+	"fmt"
 
 	"github.com/ceph/go-ceph/rgw/admin"
 	"github.com/opdev/subreconciler"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -17,12 +21,13 @@ import (
 )
 
 // Cleanup cleans up the provisioned resources for the s3UserClaim object
-func (r *Reconciler) Cleanup(ctx context.Context) (ctrl.Result, error) {
+func (r *reconcileRequest) Cleanup(ctx context.Context) (ctrl.Result, error) {
 	// Do the actual reconcile work
 	subrecs := []subreconciler.Fn{
 		r.removeCephUser,
 		r.removeS3User,
 		r.updateNamespaceQuotaStatusExclusive,
+		r.updateTeamQuotaStatusExclusive,
 		r.removeCleanupFinalizer,
 	}
 	for _, subrec := range subrecs {
@@ -35,19 +40,43 @@ func (r *Reconciler) Cleanup(ctx context.Context) (ctrl.Result, error) {
 	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 }
 
-func (r *Reconciler) removeCephUser(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) removeCephUser(ctx context.Context) (*ctrl.Result, error) {
+	// The user and its data are kept in Ceph unless the claim asks for their deletion
+	if r.deletionPolicy == consts.UserDeletionPolicyRetain || r.deletionPolicy == consts.UserDeletionPolicyOrphan {
+		r.logger.Info("keeping the Ceph user regarding the deletion policy", "deletionPolicy", r.deletionPolicy)
+		return subreconciler.ContinueReconciling()
+	}
+	// The user of a claim may have been released to another claim which adopts it
+	switch holder, err := r.findCephUserHolder(ctx); {
+	case err != nil:
+		r.logger.Error(err, "failed to find the holder of the Ceph user")
+		return subreconciler.Requeue()
+	case holder != "":
+		r.logger.Info("keeping the Ceph user which is held by another claim", "holder", holder)
+		return subreconciler.ContinueReconciling()
+	}
+
 	switch err := r.rgwClient.RemoveUser(ctx, admin.User{ID: r.cephUserFullId, PurgeData: pointer.Int(1)}); {
 	case goerrors.Is(err, admin.ErrNoSuchUser):
 		return subreconciler.ContinueReconciling()
 	case err != nil:
 		r.logger.Error(err, "failed to remove Ceph user")
+		// The claim is already gone if it's cleaned up from its S3User
+		if r.s3UserClaim.UID != "" {
+			r.recorder.Eventf(r.s3UserClaim, corev1.EventTypeWarning, consts.EventReasonDeletionBlocked,
+				"Failed to remove Ceph user %s: %s", r.cephUserFullId, err.Error())
+		}
 		return subreconciler.Requeue()
 	default:
 		return subreconciler.ContinueReconciling()
 	}
 }
 
-func (r *Reconciler) removeS3User(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) removeS3User(ctx context.Context) (*ctrl.Result, error) {
+	if r.deletionPolicy == consts.UserDeletionPolicyRetain {
+		return r.retainS3User(ctx)
+	}
+
 	s3User := &s3v1alpha1.S3User{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.s3UserName,
@@ -65,7 +94,37 @@ func (r *Reconciler) removeS3User(ctx context.Context) (*ctrl.Result, error) {
 	}
 }
 
-func (r *Reconciler) removeCleanupFinalizer(ctx context.Context) (*ctrl.Result, error) {
+// retainS3User keeps the S3User as the record of the retained Ceph user, so that it can be adopted again later
+func (r *reconcileRequest) retainS3User(ctx context.Context) (*ctrl.Result, error) {
+	s3User := &s3v1alpha1.S3User{}
+	switch err := r.Get(ctx, types.NamespacedName{Name: r.s3UserName}, s3User); {
+	case apierrors.IsNotFound(err):
+		return subreconciler.ContinueReconciling()
+	case err != nil:
+		r.logger.Error(err, "failed to get S3User")
+		return subreconciler.Requeue()
+	}
+	if s3User.Status.RetainedTime != nil {
+		return subreconciler.ContinueReconciling()
+	}
+
+	now := metav1.Now()
+	s3User.Status.RetainedTime = &now
+	meta.SetStatusCondition(&s3User.Status.Conditions, metav1.Condition{
+		Type:               consts.ConditionTypeReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             consts.ConditionReasonRetained,
+		Message:            fmt.Sprintf("the s3UserClaim is deleted and the Ceph user %q is retained", s3User.Spec.CephUserID),
+		ObservedGeneration: s3User.Generation,
+	})
+	if err := r.Status().Update(ctx, s3User); err != nil {
+		r.logger.Error(err, "failed to update S3User status")
+		return subreconciler.Requeue()
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *reconcileRequest) removeCleanupFinalizer(ctx context.Context) (*ctrl.Result, error) {
 	if r.s3UserClaim == nil {
 		return subreconciler.ContinueReconciling()
 	}
@@ -1,11 +1,14 @@
 package s3userclaim
 
 import (
+	"context"
+
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -16,14 +19,18 @@ import (
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	s3UserClassPredicate := predicates.NewS3ClassPredicate(r.s3UserClass)
+	s3UserClassPredicate := predicates.NewS3ClassPredicate(r.classResolver)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&s3v1alpha1.S3UserClaim{}, builder.WithPredicates(s3UserClassPredicate)).
 		Watches(
 			&source.Kind{Type: &s3v1alpha1.S3User{}},
 			handler.EnqueueRequestsFromMapFunc(s3UsertoS3UserClaim)).
+		Watches(
+			&source.Kind{Type: &s3v1alpha1.S3UserClass{}},
+			handler.EnqueueRequestsFromMapFunc(r.s3UserClassToS3UserClaims)).
 		Owns(&v1.Secret{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.maxConcurrentReconciles}).
 		Complete(r)
 }
 
@@ -42,3 +49,22 @@ func s3UsertoS3UserClaim(object client.Object) []reconcile.Request {
 		{NamespacedName: types.NamespacedName{Namespace: claimRef.Namespace, Name: claimRef.Name}},
 	}
 }
+
+// s3UserClassToS3UserClaims enqueues the s3UserClaims of the class, so that claims created before their class
+// get provisioned as soon as the class appears.
+func (r *Reconciler) s3UserClassToS3UserClaims(object client.Object) []reconcile.Request {
+	s3UserClaimList := &s3v1alpha1.S3UserClaimList{}
+	if err := r.List(context.Background(), s3UserClaimList); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, claim := range s3UserClaimList.Items {
+		if r.classResolver.Name(claim.Spec.S3UserClass) == object.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -9,19 +9,35 @@ package s3userclaim
 //
 // If the S3UserClaim has deletionTimestamp set or if it doesn't exist at all, the controller will try to clean up.
 // Otherwise, the controller will provision the required resources.
+//
+// The Reconciler only holds the dependencies shared by all reconciles. Each call to Reconcile creates a
+// reconcileRequest carrying the state of that request, so the controller can safely run several workers
+// (see controllers.s3UserClaim.maxConcurrentReconciles in the config).
+//
+// Before anything else, the S3UserClass of the claim is resolved and an RGW admin client is created for its Ceph
+// cluster. If the claim is already gone, the class is taken from its S3User.
 
 // Overall provisioning flow:
 //
-// 1. Create the user in Ceph
+// 1. Create the user in Ceph, or verify the existing user can be adopted and bind it by creating the S3User named after
+// the user if the claim adopts one
 // 2. Set quota for the Ceph user
 // 3. Create subuser with read access in Ceph
-// 4. Create two secrets containing S3 keys for the admin and readonly users
-// 5. Create an S3User object
-// 6. Update the status of the S3UserClaim
-// 7. Add a cleanup finalizer to the S3UserClaim
+// 4. Rotate the keys if the rotate-keys annotation has changed or the rotation interval has passed, and revoke the
+// retired keys whose grace period is over. The new keys are recorded in the status as pending before they're created
+// and an interrupted rotation is resumed with them.
+// 5. Create two secrets containing S3 keys for the admin and readonly users
+// 6. Retrieve the usage of the Ceph user once per usage sync interval
+// 7. Create an S3User object
+// 8. Add a cleanup finalizer to the S3UserClaim
+// 9. Update the status of the S3UserClaim and the S3User
+//
+// Each step records its outcome as a condition (CephUserSynced, QuotaSynced, SubusersSynced, KeyRotationSynced,
+// SecretsSynced and S3UserSynced). A failed step marks the Ready condition as false and the conditions are persisted
+// before requeueing.
 
 // Overall cleanup flow:
 //
-// 1. remove the Ceph User
-// 2. remove the S3User
+// 1. remove the Ceph User unless the deletion policy is Retain or Orphan
+// 2. remove the S3User, or mark it as retained if the deletion policy is Retain
 // 3. remove the cleanup finalizer from S3UserClaim if the object still exists
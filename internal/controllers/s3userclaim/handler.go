@@ -18,16 +18,20 @@ package s3userclaim
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/ceph/go-ceph/rgw/admin"
 	"github.com/go-logr/logr"
 	"github.com/opdev/subreconciler"
-	openshiftquota "github.com/openshift/api/quota/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -35,20 +39,35 @@ import (
 
 	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
 	"github.com/snapp-incubator/ceph-s3-operator/internal/config"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/s3userclass"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
 )
 
 type Reconciler struct {
 	client.Client
 	uncachedReader client.Reader
 	scheme         *runtime.Scheme
-	logger         logr.Logger
-	rgwClient      *admin.API
+	classResolver  *s3userclass.Resolver
+	recorder       record.EventRecorder
+
+	// configurations
+	maxConcurrentReconciles int
+	usageSyncInterval       time.Duration
+}
+
+// reconcileRequest holds the state of a single reconciliation. A new one is created on each call to Reconcile
+// so that concurrent reconciles never share any mutable state.
+type reconcileRequest struct {
+	*Reconciler
+	logger logr.Logger
+	// s3UserClass is the resolved class of the s3UserClaim and rgwClient talks to its Ceph cluster
+	s3UserClass *s3userclass.Class
+	rgwClient   *admin.API
 
-	// reconcile specific variables
-	clusterResourceQuota      *openshiftquota.ClusterResourceQuota
 	s3UserClaim               *s3v1alpha1.S3UserClaim
 	cephUser                  admin.User
 	s3UserClaimNamespace      string
+	s3UserClaimName           string
 	cephTenant                string
 	cephUserId                string
 	cephUserFullId            string
@@ -57,27 +76,26 @@ type Reconciler struct {
 	readonlyCephUserId        string
 	readonlyCephUserFullId    string
 	desiredSubusersStringList []string
+	quota                     *s3v1alpha1.UserQuota
+	deletionPolicy            string
 	namespaceUsedQuota        *s3v1alpha1.UserQuota
-	// configurations
-	clusterName  string
-	rgwAccessKey string
-	rgwSecretKey string
-	rgwEndpoint  string
-	s3UserClass  string
+	keyRotation               *s3v1alpha1.KeyRotationStatus
+	usage                     *s3v1alpha1.UserUsage
+	conditions                []metav1.Condition
+	// requeueAfter is the delay of the next reconcile after a successful one, zero means no requeue
+	requeueAfter time.Duration
 }
 
-func NewReconciler(mgr manager.Manager, cfg *config.Config, rgwClient *admin.API) *Reconciler {
+func NewReconciler(mgr manager.Manager, cfg *config.Config) *Reconciler {
 	return &Reconciler{
 		Client:         mgr.GetClient(),
 		uncachedReader: mgr.GetAPIReader(),
 		scheme:         mgr.GetScheme(),
-		rgwClient:      rgwClient,
+		classResolver:  s3userclass.NewResolver(mgr.GetClient(), cfg),
+		recorder:       mgr.GetEventRecorderFor("s3userclaim-controller"),
 
-		s3UserClass:  cfg.S3UserClass,
-		clusterName:  cfg.ClusterName,
-		rgwAccessKey: cfg.Rgw.AccessKey,
-		rgwSecretKey: cfg.Rgw.SecretKey,
-		rgwEndpoint:  cfg.Rgw.Endpoint,
+		maxConcurrentReconciles: cfg.Controllers.S3UserClaim.MaxConcurrentReconciles,
+		usageSyncInterval:       time.Duration(cfg.UsageSyncIntervalSeconds) * time.Second,
 	}
 }
 
@@ -87,50 +105,159 @@ func NewReconciler(mgr manager.Manager, cfg *config.Config, rgwClient *admin.API
 //+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3users,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3users/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3users/finalizers,verbs=update
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3userclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3teamquotas,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=quota.openshift.io,resources=clusterresourcequotas,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=quota.openshift.io,resources=clusterresourcequotas/status,verbs=get;update;patch
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.logger = log.FromContext(ctx)
-	r.s3UserClaim = &s3v1alpha1.S3UserClaim{}
-	r.initVars(req)
+	rr := &reconcileRequest{
+		Reconciler:  r,
+		logger:      log.FromContext(ctx),
+		s3UserClaim: &s3v1alpha1.S3UserClaim{},
+	}
+	return rr.reconcile(ctx, req)
+}
 
+func (r *reconcileRequest) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	switch err := r.Get(ctx, req.NamespacedName, r.s3UserClaim); {
 	case apierrors.IsNotFound(err):
+		if err := r.initVarsForCleanup(ctx, req); err != nil {
+			r.logger.Error(err, "failed to resolve s3UserClass")
+			return subreconciler.Evaluate(subreconciler.Requeue())
+		}
 		return r.Cleanup(ctx)
 	case err != nil:
 		r.logger.Error(err, "failed to fetch object")
 		return subreconciler.Evaluate(subreconciler.Requeue())
-	default:
-		if r.s3UserClaim.ObjectMeta.DeletionTimestamp != nil {
-			return r.Cleanup(ctx)
-		}
+	}
+
+	r.conditions = r.s3UserClaim.Status.DeepCopy().Conditions
+	if err := r.initVars(ctx, req, r.s3UserClaim.Spec.S3UserClass, r.s3UserClaim.Spec.ExistingUser); err != nil {
+		r.logger.Error(err, "failed to resolve s3UserClass")
+		r.setCondition(consts.ConditionTypeS3UserClassResolved, err)
+		r.updateS3UserClaimConditions(ctx)
+		return subreconciler.Evaluate(subreconciler.Requeue())
+	}
+	r.setCondition(consts.ConditionTypeS3UserClassResolved, nil)
+	r.deletionPolicy = r.s3UserClaim.GetDeletionPolicy()
+
+	if r.s3UserClaim.ObjectMeta.DeletionTimestamp != nil {
+		return r.Cleanup(ctx)
 	}
 	return r.Provision(ctx)
 }
 
-func (r *Reconciler) initVars(req ctrl.Request) {
-	r.s3UserClaimNamespace = req.Namespace
+// initVarsForCleanup initializes the variables when the s3UserClaim is already gone. The class is then taken from the
+// S3User of the claim, falling back to the default class. Without the S3User there's no record of the Ceph user and
+// its deletion policy, so the Ceph user is left untouched.
+func (r *reconcileRequest) initVarsForCleanup(ctx context.Context, req ctrl.Request) error {
+	s3User, err := r.getClaimS3User(ctx, req)
+	switch {
+	case err != nil:
+		return err
+	case s3User == nil:
+		s3User = &s3v1alpha1.S3User{}
+		r.deletionPolicy = consts.UserDeletionPolicyOrphan
+	default:
+		r.deletionPolicy = s3User.Spec.DeletionPolicy
+	}
+	if err := r.initVars(ctx, req, s3User.Spec.S3UserClass, s3User.Spec.CephUserID); err != nil {
+		return err
+	}
+	// The S3User of an adopted user is named after the user rather than the claim
+	if s3User.Name != "" {
+		r.s3UserName = s3User.Name
+	}
+	return nil
+}
 
-	// Only alphanumeric characters and underscore are allowed for tenant name
-	k8sNameSpecialChars := regexp.MustCompile(`[.-]`)
-	namespace := k8sNameSpecialChars.ReplaceAllString(req.Namespace, "_")
-	clusterName := k8sNameSpecialChars.ReplaceAllString(r.clusterName, "_")
-	r.cephTenant = fmt.Sprintf("%s__%s", clusterName, namespace)
+// getClaimS3User returns the S3User of the claim, if any. It's named after the claim unless the claim adopts its
+// Ceph user.
+func (r *reconcileRequest) getClaimS3User(ctx context.Context, req ctrl.Request) (*s3v1alpha1.S3User, error) {
+	s3User := &s3v1alpha1.S3User{}
+	switch err := r.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s.%s", req.Namespace, req.Name)}, s3User); {
+	case err == nil:
+		return s3User, nil
+	case !apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("failed to get s3User, %w", err)
+	}
 
-	r.cephUserId = req.Name
+	s3UserList := &s3v1alpha1.S3UserList{}
+	if err := r.List(ctx, s3UserList); err != nil {
+		return nil, fmt.Errorf("failed to list s3Users, %w", err)
+	}
+	for i := range s3UserList.Items {
+		if isBoundToClaim(&s3UserList.Items[i], req.Namespace, req.Name) {
+			return &s3UserList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// initVars initializes the variables of the request. The Ceph user is the existing user if it's given, otherwise
+// the user named after the claim.
+func (r *reconcileRequest) initVars(ctx context.Context, req ctrl.Request, s3UserClass, existingUser string) error {
+	var err error
+	if r.s3UserClass, err = r.classResolver.Resolve(ctx, s3UserClass); err != nil {
+		return err
+	}
+	if r.rgwClient, err = r.s3UserClass.RgwClient(); err != nil {
+		return fmt.Errorf("failed to create rgw client, %w", err)
+	}
+	if r.quota, err = s3v1alpha1.GetS3UserClaimQuota(ctx, r.Client, r.s3UserClaim); err != nil {
+		return err
+	}
+
+	r.s3UserClaimNamespace = req.Namespace
+	r.s3UserClaimName = req.Name
+	r.cephTenant, r.cephUserId = r.s3UserClass.CephUser(req.Namespace, req.Name, existingUser)
 
 	// Ceph-SDK functions that involve retrieving the user such as GetQuota, GetUser and even SetUser,
 	// required tenant name in UID field.
-	r.cephUserFullId = fmt.Sprintf("%s$%s", r.cephTenant, r.cephUserId)
-	r.cephDisplayName = fmt.Sprintf("%s in %s.%s", req.Name, req.Namespace, r.clusterName)
+	r.cephUserFullId = s3userclass.FullUserID(r.cephTenant, r.cephUserId)
+	r.cephDisplayName = fmt.Sprintf("%s in %s.%s", req.Name, req.Namespace, r.s3UserClass.ClusterName)
 
 	r.readonlyCephUserId = "readonly"
 	r.readonlyCephUserFullId = fmt.Sprintf("%s:%s", r.cephUserFullId, r.readonlyCephUserId)
 
 	r.s3UserName = fmt.Sprintf("%s.%s", req.Namespace, req.Name)
+	if existingUser != "" {
+		r.s3UserName = generateAdoptedS3UserName(r.s3UserClass.Name, r.cephUserFullId)
+	}
+	return nil
+}
+
+// Only lowercase alphanumeric characters and dash are kept from the ID of an adopted user in the name of its S3User,
+// whose length leaves room for the hash suffix
+var s3UserNameSpecialChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+const maxAdoptedS3UserNamePrefixLength = 200
+
+// generateAdoptedS3UserName returns the name of the S3User of an adopted Ceph user. Naming the S3User after the user
+// lets the API server refuse a second S3User of the user, so only one claim can bind it. The name is the user ID made
+// a valid object name, suffixed with a hash of the class and the user ID so that distinct users never share it. Unlike
+// the names of the S3Users of the other claims, it never contains a dot.
+func generateAdoptedS3UserName(s3UserClassName, cephUserFullId string) string {
+	hash := sha256.Sum256([]byte(s3UserClassName + "/" + cephUserFullId))
+	name := strings.Trim(s3UserNameSpecialChars.ReplaceAllString(strings.ToLower(cephUserFullId), "-"), "-")
+	if len(name) > maxAdoptedS3UserNamePrefixLength {
+		name = strings.TrimRight(name[:maxAdoptedS3UserNamePrefixLength], "-")
+	}
+	if name == "" {
+		return fmt.Sprintf("%x", hash[:4])
+	}
+	return fmt.Sprintf("%s-%x", name, hash[:4])
+}
+
+// isBoundToClaim reports whether the S3User belongs to the claim with the given namespace and name
+func isBoundToClaim(s3User *s3v1alpha1.S3User, namespace, name string) bool {
+	claimRef := s3User.Spec.ClaimRef
+	return claimRef != nil && claimRef.Namespace == namespace && claimRef.Name == name
 }
 
 func generateSubuserFullId(cephUserFullId string, subuser string) string {
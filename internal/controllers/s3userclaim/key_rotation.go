@@ -0,0 +1,259 @@
+package s3userclaim
+
+import (
+	"context"
+	"crypto/rand"
+	goerrors "errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/opdev/subreconciler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// rotateKeys rotates the keys of the Ceph user and its subusers when the rotate-keys annotation has changed or the
+// rotation interval has passed. Each user gets a new key which the secrets are updated with later on. The previous
+// keys are retired and stay valid until their grace period is over. The new keys are recorded in the status as pending
+// before they're created, so a rotation which is interrupted is resumed with the same keys instead of leaving keys
+// behind which nothing ever revokes.
+func (r *reconcileRequest) rotateKeys(ctx context.Context) (*ctrl.Result, error) {
+	r.keyRotation = r.s3UserClaim.Status.KeyRotation.DeepCopy()
+	if r.keyRotation == nil {
+		r.keyRotation = &s3v1alpha1.KeyRotationStatus{}
+	}
+
+	now := time.Now()
+	if len(r.keyRotation.PendingKeys) == 0 {
+		if !r.isKeyRotationDue(now) {
+			r.setCondition(consts.ConditionTypeKeyRotationSynced, nil)
+			return subreconciler.ContinueReconciling()
+		}
+		if err := r.recordPendingKeys(ctx); err != nil {
+			r.logger.Error(err, "failed to record pending keys")
+			r.setCondition(consts.ConditionTypeKeyRotationSynced, fmt.Errorf("failed to record pending keys, %w", err))
+			return subreconciler.Requeue()
+		}
+	}
+
+	keyUsers := map[string]bool{}
+	for _, user := range r.keyUsers() {
+		keyUsers[user] = true
+	}
+	revokeAfter := metav1.NewTime(now.Add(r.keyRotationGracePeriod()))
+	for _, pendingKey := range r.keyRotation.PendingKeys {
+		// The key of a subuser which is removed meanwhile is dropped along with the subuser
+		if !keyUsers[pendingKey.User] {
+			continue
+		}
+
+		var oldKeys []admin.UserKeySpec
+		created := false
+		for _, key := range r.activeKeys(pendingKey.User) {
+			if key.AccessKey == pendingKey.AccessKey {
+				created = true
+				continue
+			}
+			oldKeys = append(oldKeys, key)
+		}
+
+		if !created {
+			secretKey, err := generateKeyString(consts.CephSecretKeyLength, secretKeyChars)
+			if err != nil {
+				r.logger.Error(err, "failed to generate secret key", "user", pendingKey.User)
+				r.setCondition(consts.ConditionTypeKeyRotationSynced,
+					fmt.Errorf("failed to generate secret key of %s, %w", pendingKey.User, err))
+				return subreconciler.Requeue()
+			}
+			newKey := admin.UserKeySpec{
+				UID:       r.cephUserFullId,
+				KeyType:   consts.CephKeyTypeS3,
+				AccessKey: pendingKey.AccessKey,
+				SecretKey: secretKey,
+			}
+			if pendingKey.User != r.cephUserFullId {
+				newKey.SubUser = pendingKey.User
+			}
+			if _, err := r.rgwClient.CreateKey(ctx, newKey); err != nil {
+				r.logger.Error(err, "failed to create key", "user", pendingKey.User)
+				r.setCondition(consts.ConditionTypeKeyRotationSynced,
+					fmt.Errorf("failed to create key of %s, %w", pendingKey.User, err))
+				return subreconciler.Requeue()
+			}
+		}
+
+		for _, key := range oldKeys {
+			r.keyRotation.RetiredKeys = append(r.keyRotation.RetiredKeys, s3v1alpha1.RetiredKey{
+				User:        pendingKey.User,
+				AccessKey:   key.AccessKey,
+				RevokeAfter: revokeAfter,
+			})
+		}
+	}
+
+	r.keyRotation.PendingKeys = nil
+	r.keyRotation.LastRotationTime = &metav1.Time{Time: now}
+	r.keyRotation.LastRotationTrigger = r.s3UserClaim.Annotations[consts.AnnotationRotateKeys]
+	r.requeueAfterAtMost(r.keyRotationGracePeriod())
+	if interval := r.keyRotationInterval(); interval > 0 {
+		r.requeueAfterAtMost(interval)
+	}
+
+	// retrieve the ceph user again to have the new keys at hand
+	result, err := r.retrieveCephUser(ctx)
+	if subreconciler.ShouldHaltOrRequeue(result, err) {
+		return result, err
+	}
+
+	r.setCondition(consts.ConditionTypeKeyRotationSynced, nil)
+	return subreconciler.ContinueReconciling()
+}
+
+// recordPendingKeys generates the access keys of the new keys of the users and persists them in the status
+func (r *reconcileRequest) recordPendingKeys(ctx context.Context) error {
+	for _, user := range r.keyUsers() {
+		accessKey, err := generateKeyString(consts.CephAccessKeyLength, accessKeyChars)
+		if err != nil {
+			return fmt.Errorf("failed to generate access key of %s, %w", user, err)
+		}
+		r.keyRotation.PendingKeys = append(r.keyRotation.PendingKeys, s3v1alpha1.PendingKey{
+			User:      user,
+			AccessKey: accessKey,
+		})
+	}
+
+	status := r.s3UserClaim.Status.DeepCopy()
+	status.KeyRotation = r.keyRotation.DeepCopy()
+	return r.updateStatus(ctx, *status)
+}
+
+// revokeRetiredKeys removes the retired keys whose grace period is over
+func (r *reconcileRequest) revokeRetiredKeys(ctx context.Context) (*ctrl.Result, error) {
+	now := time.Now()
+	var remainingKeys []s3v1alpha1.RetiredKey
+	for _, retiredKey := range r.keyRotation.RetiredKeys {
+		if now.Before(retiredKey.RevokeAfter.Time) {
+			remainingKeys = append(remainingKeys, retiredKey)
+			r.requeueAfterAtMost(retiredKey.RevokeAfter.Sub(now))
+			continue
+		}
+
+		err := r.rgwClient.RemoveKey(ctx, admin.UserKeySpec{
+			UID:       r.cephUserFullId,
+			AccessKey: retiredKey.AccessKey,
+			KeyType:   consts.CephKeyTypeS3,
+		})
+		if err != nil && !goerrors.Is(err, admin.ErrInvalidAccessKey) && !goerrors.Is(err, admin.ErrNoSuchKey) {
+			r.logger.Error(err, "failed to revoke key", "user", retiredKey.User)
+			r.setCondition(consts.ConditionTypeKeyRotationSynced,
+				fmt.Errorf("failed to revoke retired key of %s, %w", retiredKey.User, err))
+			return subreconciler.Requeue()
+		}
+	}
+	r.keyRotation.RetiredKeys = remainingKeys
+	if r.keyRotation.LastRotationTime == nil && len(r.keyRotation.RetiredKeys) == 0 {
+		r.keyRotation = nil
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *reconcileRequest) isKeyRotationDue(now time.Time) bool {
+	trigger := r.s3UserClaim.Annotations[consts.AnnotationRotateKeys]
+	if trigger != "" && trigger != r.keyRotation.LastRotationTrigger {
+		return true
+	}
+
+	interval := r.keyRotationInterval()
+	if interval <= 0 {
+		return false
+	}
+	lastRotation := r.s3UserClaim.CreationTimestamp.Time
+	if r.keyRotation.LastRotationTime != nil {
+		lastRotation = r.keyRotation.LastRotationTime.Time
+	}
+	nextRotation := lastRotation.Add(interval)
+	if now.Before(nextRotation) {
+		r.requeueAfterAtMost(nextRotation.Sub(now))
+		return false
+	}
+	return true
+}
+
+func (r *reconcileRequest) keyRotationInterval() time.Duration {
+	keyRotation := r.s3UserClaim.Spec.KeyRotation
+	if keyRotation == nil || keyRotation.Interval == nil {
+		return 0
+	}
+	return keyRotation.Interval.Duration
+}
+
+func (r *reconcileRequest) keyRotationGracePeriod() time.Duration {
+	keyRotation := r.s3UserClaim.Spec.KeyRotation
+	if keyRotation == nil || keyRotation.GracePeriod == nil {
+		return consts.DefaultKeyRotationGracePeriod
+	}
+	return keyRotation.GracePeriod.Duration
+}
+
+// keyUsers returns the Ceph user and subusers whose keys are stored in secrets
+func (r *reconcileRequest) keyUsers() []string {
+	users := []string{r.cephUserFullId, r.readonlyCephUserFullId}
+	for _, subuser := range r.desiredSubusersStringList {
+		users = append(users, generateSubuserFullId(r.cephUserFullId, subuser))
+	}
+	return users
+}
+
+// activeKeys returns the keys of the given user which are not retired
+func (r *reconcileRequest) activeKeys(userName string) []admin.UserKeySpec {
+	var keys []admin.UserKeySpec
+	for _, key := range r.cephUser.Keys {
+		if key.User == userName && !r.isRetiredKey(key.AccessKey) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (r *reconcileRequest) isRetiredKey(accessKey string) bool {
+	if r.keyRotation == nil {
+		return false
+	}
+	for _, retiredKey := range r.keyRotation.RetiredKeys {
+		if retiredKey.AccessKey == accessKey {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	accessKeyChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	secretKeyChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// generateKeyString returns a random string of the given length made of the given characters
+func generateKeyString(length int, chars string) (string, error) {
+	key := make([]byte, length)
+	for i := range key {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			return "", err
+		}
+		key[i] = chars[n.Int64()]
+	}
+	return string(key), nil
+}
+
+// requeueAfterAtMost makes sure the request is requeued after the given duration at the latest
+func (r *reconcileRequest) requeueAfterAtMost(duration time.Duration) {
+	if r.requeueAfter == 0 || duration < r.requeueAfter {
+		r.requeueAfter = duration
+	}
+}
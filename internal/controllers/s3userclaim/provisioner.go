@@ -4,12 +4,14 @@ import (
 	"context"
 	goerrors "errors"
 	"fmt"
+	"time"
 
 	"github.com/ceph/go-ceph/rgw/admin"
 	"github.com/opdev/subreconciler"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -25,37 +27,49 @@ import (
 )
 
 // Provision provisions the required resources for the s3UserClaim object
-func (r *Reconciler) Provision(ctx context.Context) (ctrl.Result, error) {
+func (r *reconcileRequest) Provision(ctx context.Context) (ctrl.Result, error) {
 	// Do the actual reconcile work
 	subrecs := []subreconciler.Fn{
+		r.verifyAdoption,
+		r.bindAdoptedUser,
 		r.ensureCephUser,
 		r.ensureCephUserQuota,
 		r.syncSubusersList,
 		// retrieve the ceph user to have keys of subuser at hand
 		r.retrieveCephUser,
+		r.rotateKeys,
+		r.revokeRetiredKeys,
 		r.ensureAdminSecret,
 		r.ensureReadonlySecret,
 		r.ensureOtherSubusersSecret,
+		r.syncUsage,
 		r.ensureS3User,
-		r.updateS3UserClaimStatus,
 		r.updateNamespaceQuotaStatusInclusive,
+		r.updateTeamQuotaStatusInclusive,
+		r.checkQuotaThresholds,
 		r.addCleanupFinalizer,
+		r.updateS3UserClaimStatus,
 	}
 	for _, subrec := range subrecs {
 		result, err := subrec(ctx)
 		if subreconciler.ShouldHaltOrRequeue(result, err) {
+			// Persist the conditions so the reason of the failure is visible on the object
+			r.updateS3UserClaimConditions(ctx)
 			return subreconciler.Evaluate(result, err)
 		}
 	}
 
+	if r.requeueAfter > 0 {
+		return subreconciler.Evaluate(subreconciler.RequeueWithDelay(r.requeueAfter))
+	}
 	return subreconciler.Evaluate(subreconciler.DoNotRequeue())
 }
 
-func (r *Reconciler) ensureCephUser(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) ensureCephUser(ctx context.Context) (*ctrl.Result, error) {
 	desiredUser := admin.User{
 		ID:          r.cephUserFullId,
 		DisplayName: r.cephDisplayName,
-		MaxBuckets:  pointer.Int(int(r.s3UserClaim.Spec.Quota.MaxBuckets)),
+		MaxBuckets:  pointer.Int(int(r.quota.MaxBuckets)),
 	}
 	logger := r.logger.WithValues("userId", desiredUser.ID)
 
@@ -65,34 +79,44 @@ func (r *Reconciler) ensureCephUser(ctx context.Context) (*ctrl.Result, error) {
 			existingUser, err = r.rgwClient.ModifyUser(ctx, desiredUser)
 			if err != nil {
 				logger.Error(err, "failed to update ceph user", "userId", desiredUser.ID)
+				r.setCondition(consts.ConditionTypeCephUserSynced, fmt.Errorf("failed to update ceph user, %w", err))
 				return subreconciler.Requeue()
 			}
 		}
 		r.cephUser = existingUser
+	case goerrors.Is(err, admin.ErrNoSuchUser) && r.s3UserClaim.Spec.ExistingUser != "":
+		logger.Error(err, "failed to find the ceph user to adopt")
+		r.setCondition(consts.ConditionTypeCephUserSynced, consts.ErrCephUserNotFound)
+		return subreconciler.Requeue()
 	case goerrors.Is(err, admin.ErrNoSuchUser):
 		user, err := r.rgwClient.CreateUser(ctx, desiredUser)
 		if err != nil {
 			logger.Error(err, "failed to create ceph user", "userId", desiredUser.ID)
+			r.setCondition(consts.ConditionTypeCephUserSynced, fmt.Errorf("failed to create ceph user, %w", err))
 			return subreconciler.Requeue()
 		}
 		r.cephUser = user
+		r.recorder.Eventf(r.s3UserClaim, corev1.EventTypeNormal, consts.EventReasonUserCreated,
+			"Created Ceph user %s", desiredUser.ID)
 	default:
 		logger.Error(err, "failed to get ceph user", "userId", desiredUser.ID)
+		r.setCondition(consts.ConditionTypeCephUserSynced, fmt.Errorf("failed to get ceph user, %w", err))
 		return subreconciler.Requeue()
 	}
+	r.setCondition(consts.ConditionTypeCephUserSynced, nil)
 	// retrieve desiredSubusers as string list
 	r.desiredSubusersStringList = retrieveSubusersString(r.s3UserClaim.Spec.Subusers)
 
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *Reconciler) ensureCephUserQuota(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) ensureCephUserQuota(ctx context.Context) (*ctrl.Result, error) {
 	desiredQuota := admin.QuotaSpec{
 		UID:        r.cephUserFullId,
 		QuotaType:  consts.QuotaTypeUser,
 		Enabled:    pointer.Bool(true),
-		MaxSize:    pointer.Int64(r.s3UserClaim.Spec.Quota.MaxSize.Value()),
-		MaxObjects: pointer.Int64(r.s3UserClaim.Spec.Quota.MaxObjects.Value()),
+		MaxSize:    pointer.Int64(r.quota.MaxSize.Value()),
+		MaxObjects: pointer.Int64(r.quota.MaxObjects.Value()),
 	}
 
 	switch existingQuota, err := r.rgwClient.GetUserQuota(ctx, desiredQuota); {
@@ -104,14 +128,19 @@ func (r *Reconciler) ensureCephUserQuota(ctx context.Context) (*ctrl.Result, err
 			*existingQuota.MaxObjects != *desiredQuota.MaxObjects {
 			if err := r.rgwClient.SetUserQuota(ctx, desiredQuota); err != nil {
 				r.logger.Error(err, "failed to set user quota", "userId", desiredQuota.UID)
+				r.setCondition(consts.ConditionTypeQuotaSynced, fmt.Errorf("failed to set user quota, %w", err))
 				return subreconciler.Requeue()
 			}
+			r.recorder.Eventf(r.s3UserClaim, corev1.EventTypeNormal, consts.EventReasonQuotaChanged,
+				"Set quota of Ceph user to maxSize=%d, maxObjects=%d", *desiredQuota.MaxSize, *desiredQuota.MaxObjects)
 		}
 
 		r.cephUser.UserQuota = desiredQuota
+		r.setCondition(consts.ConditionTypeQuotaSynced, nil)
 		return subreconciler.ContinueReconciling()
 	default:
 		r.logger.Error(err, "failed to get user quota")
+		r.setCondition(consts.ConditionTypeQuotaSynced, fmt.Errorf("failed to get user quota, %w", err))
 		return subreconciler.Requeue()
 	}
 }
@@ -123,7 +152,7 @@ func (r *Reconciler) ensureCephUserQuota(ctx context.Context) (*ctrl.Result, err
 // 1. Subusers which are in the spec list and not in the current ceph users list will be created.
 // 2. Subusers which are not in the spec list but are in the current ceph users list will be removed with their secrets.
 // 3. Subusers which are common in the both lists will be deleted from the map; hence, no action happens on them.
-func (r *Reconciler) syncSubusersList(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) syncSubusersList(ctx context.Context) (*ctrl.Result, error) {
 
 	subuserFullIdAccess := r.generateSubuserAccess(r.desiredSubusersStringList,
 		r.cephUser.Subusers)
@@ -137,21 +166,31 @@ func (r *Reconciler) syncSubusersList(ctx context.Context) (*ctrl.Result, error)
 		}
 		if tag == consts.SubuserTagCreate {
 			if err := r.generateSubuser(ctx, r.cephUserFullId, desiredSubuser); err != nil {
+				r.setCondition(consts.ConditionTypeSubusersSynced,
+					fmt.Errorf("failed to create subuser %s, %w", desiredSubuser.Name, err))
 				return subreconciler.Requeue()
 			}
+			r.recorder.Eventf(r.s3UserClaim, corev1.EventTypeNormal, consts.EventReasonSubuserAdded,
+				"Created subuser %s", desiredSubuser.Name)
 		} else {
 			if err := r.removeSubuserAndSecret(ctx, r.cephUserFullId, desiredSubuser); err != nil {
+				r.setCondition(consts.ConditionTypeSubusersSynced,
+					fmt.Errorf("failed to remove subuser %s, %w", desiredSubuser.Name, err))
 				return subreconciler.Requeue()
 			}
+			r.recorder.Eventf(r.s3UserClaim, corev1.EventTypeNormal, consts.EventReasonSubuserRemoved,
+				"Removed subuser %s and its secret", desiredSubuser.Name)
 		}
 	}
 
+	r.setCondition(consts.ConditionTypeSubusersSynced, nil)
 	return subreconciler.ContinueReconciling()
 }
-func (r *Reconciler) retrieveCephUser(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) retrieveCephUser(ctx context.Context) (*ctrl.Result, error) {
 	retrievedUser, err := r.rgwClient.GetUser(ctx, admin.User{ID: r.cephUserFullId})
 	if err != nil {
 		r.logger.Error(err, "failed to retrieve ceph user")
+		r.setCondition(consts.ConditionTypeCephUserSynced, fmt.Errorf("failed to retrieve ceph user, %w", err))
 		return subreconciler.Requeue()
 	}
 
@@ -159,31 +198,35 @@ func (r *Reconciler) retrieveCephUser(ctx context.Context) (*ctrl.Result, error)
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *Reconciler) ensureAdminSecret(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) ensureAdminSecret(ctx context.Context) (*ctrl.Result, error) {
 	assembledSecret, err := r.assembleCephUserSecret(r.cephUserFullId, r.s3UserClaim.Spec.AdminSecret)
 	if err != nil {
 		r.logger.Error(err, "failed to assemble admin secret")
+		r.setCondition(consts.ConditionTypeSecretsSynced, fmt.Errorf("failed to assemble admin secret, %w", err))
 		return subreconciler.Requeue()
 	}
 	return r.ensureSecret(ctx, assembledSecret)
 }
 
-func (r *Reconciler) ensureReadonlySecret(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) ensureReadonlySecret(ctx context.Context) (*ctrl.Result, error) {
 	assembledSecret, err := r.assembleCephUserSecret(r.readonlyCephUserFullId, r.s3UserClaim.Spec.ReadonlySecret)
 	if err != nil {
 		r.logger.Error(err, "failed to assemble readonly secret")
+		r.setCondition(consts.ConditionTypeSecretsSynced, fmt.Errorf("failed to assemble readonly secret, %w", err))
 		return subreconciler.Requeue()
 	}
 	return r.ensureSecret(ctx, assembledSecret)
 }
 
-func (r *Reconciler) ensureOtherSubusersSecret(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) ensureOtherSubusersSecret(ctx context.Context) (*ctrl.Result, error) {
 	for _, subuser := range r.desiredSubusersStringList {
 		cephSubuserFullId := generateSubuserFullId(r.cephUserFullId, subuser)
 		SubuserSecretName := generateSubuserSecretName(r.s3UserClaim.Name, subuser)
 		assembledSecret, err := r.assembleCephUserSecret(cephSubuserFullId, SubuserSecretName)
 		if err != nil {
 			r.logger.Error(err, "failed to assemble other subusers secret")
+			r.setCondition(consts.ConditionTypeSecretsSynced,
+				fmt.Errorf("failed to assemble secret of subuser %s, %w", subuser, err))
 			return subreconciler.Requeue()
 		}
 		result, err := r.ensureSecret(ctx, assembledSecret)
@@ -191,79 +234,257 @@ func (r *Reconciler) ensureOtherSubusersSecret(ctx context.Context) (*ctrl.Resul
 			return result, err
 		}
 	}
+	r.setCondition(consts.ConditionTypeSecretsSynced, nil)
+	return subreconciler.ContinueReconciling()
+}
+
+// syncUsage retrieves the actual usage of the Ceph user. The usage is refreshed once per usage sync interval so that
+// the status isn't updated on every reconcile. A zero interval disables the usage sync.
+func (r *reconcileRequest) syncUsage(ctx context.Context) (*ctrl.Result, error) {
+	r.usage = r.s3UserClaim.Status.Usage
+	if r.usageSyncInterval <= 0 {
+		return subreconciler.ContinueReconciling()
+	}
+	now := time.Now()
+	if r.usage != nil && r.usage.LastUpdateTime != nil {
+		nextSync := r.usage.LastUpdateTime.Add(r.usageSyncInterval)
+		if now.Before(nextSync) {
+			r.requeueAfterAtMost(nextSync.Sub(now))
+			return subreconciler.ContinueReconciling()
+		}
+	}
+	r.requeueAfterAtMost(r.usageSyncInterval)
+
+	// Failing to retrieve the usage doesn't affect the provisioning, the usage is retried on the next sync
+	cephUser, err := r.rgwClient.GetUser(ctx, admin.User{ID: r.cephUserFullId, GenerateStat: pointer.Bool(true)})
+	if err != nil {
+		r.logger.Error(err, "failed to retrieve ceph user stats")
+		return subreconciler.ContinueReconciling()
+	}
+	buckets, err := r.rgwClient.ListUsersBuckets(ctx, r.cephUserFullId)
+	if err != nil {
+		r.logger.Error(err, "failed to list ceph user buckets")
+		return subreconciler.ContinueReconciling()
+	}
+
+	var size, objects int64
+	if cephUser.Stat.Size != nil {
+		size = int64(*cephUser.Stat.Size)
+	}
+	if cephUser.Stat.NumObjects != nil {
+		objects = int64(*cephUser.Stat.NumObjects)
+	}
+	r.usage = &s3v1alpha1.UserUsage{
+		Size:              *resource.NewQuantity(size, resource.BinarySI),
+		Objects:           *resource.NewQuantity(objects, resource.DecimalSI),
+		Buckets:           int64(len(buckets)),
+		SizePercentage:    percentage(size, r.quota.MaxSize.Value()),
+		ObjectsPercentage: percentage(objects, r.quota.MaxObjects.Value()),
+		BucketsPercentage: percentage(int64(len(buckets)), r.quota.MaxBuckets),
+		LastUpdateTime:    &metav1.Time{Time: now},
+	}
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *Reconciler) ensureS3User(ctx context.Context) (*ctrl.Result, error) {
+func percentage(used, limit int64) int64 {
+	if limit <= 0 {
+		return 0
+	}
+	return used * 100 / limit
+}
+
+func (r *reconcileRequest) ensureS3User(ctx context.Context) (*ctrl.Result, error) {
 	existingS3User := &s3v1alpha1.S3User{}
 
-	switch err := r.Get(ctx, types.NamespacedName{Name: r.s3UserName}, existingS3User); {
+	// The S3User of an adopted user may have just been created by bindAdoptedUser, so it's not read from the cache
+	switch err := r.uncachedReader.Get(ctx, types.NamespacedName{Name: r.s3UserName}, existingS3User); {
 	case apierrors.IsNotFound(err):
 		s3user, err := r.assembleS3User()
 		if err != nil {
 			r.logger.Error(err, "failed to assemble s3 user")
+			r.setCondition(consts.ConditionTypeS3UserSynced, fmt.Errorf("failed to assemble s3 user, %w", err))
 			return subreconciler.Requeue()
 		}
 		if err := r.Create(ctx, s3user); err != nil {
 			r.logger.Error(err, "failed to create s3 user")
+			r.setCondition(consts.ConditionTypeS3UserSynced, fmt.Errorf("failed to create s3 user, %w", err))
 			return subreconciler.Requeue()
 		}
+		r.setCondition(consts.ConditionTypeS3UserSynced, nil)
 		return subreconciler.ContinueReconciling()
 	case err != nil:
 		r.logger.Error(err, "failed to get s3 user")
+		r.setCondition(consts.ConditionTypeS3UserSynced, fmt.Errorf("failed to get s3 user, %w", err))
 		return subreconciler.Requeue()
 	default:
 		desiredS3user, err := r.assembleS3User()
 		if err != nil {
 			r.logger.Error(err, "failed to assemble s3 user")
+			r.setCondition(consts.ConditionTypeS3UserSynced, fmt.Errorf("failed to assemble s3 user, %w", err))
 			return subreconciler.Requeue()
 		}
 		if !apiequality.Semantic.DeepEqual(desiredS3user.Spec, existingS3User.Spec) {
 			existingS3User.Spec = *desiredS3user.Spec.DeepCopy()
 			if err := r.Update(ctx, existingS3User); err != nil {
 				r.logger.Error(err, "failed to update s3 user")
+				r.setCondition(consts.ConditionTypeS3UserSynced, fmt.Errorf("failed to update s3 user, %w", err))
 				return subreconciler.Requeue()
 			}
 		}
+		r.setCondition(consts.ConditionTypeS3UserSynced, nil)
 		return subreconciler.ContinueReconciling()
 	}
 }
 
-func (r *Reconciler) updateS3UserClaimStatus(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) updateS3UserClaimStatus(ctx context.Context) (*ctrl.Result, error) {
+	r.setReadyCondition(metav1.ConditionTrue, consts.ConditionReasonProvisioned, "")
 	status := s3v1alpha1.S3UserClaimStatus{
-		Quota:      r.s3UserClaim.Spec.Quota,
-		S3UserName: r.s3UserName,
-		Subusers:   r.s3UserClaim.Spec.Subusers,
+		Quota:              r.quota,
+		S3UserName:         r.s3UserName,
+		Subusers:           r.s3UserClaim.Spec.Subusers,
+		Usage:              r.usage,
+		KeyRotation:        r.keyRotation,
+		ObservedGeneration: r.s3UserClaim.Generation,
+		Conditions:         r.conditions,
 	}
 
-	if !apiequality.Semantic.DeepEqual(r.s3UserClaim.Status, status) {
-		r.s3UserClaim.Status = status
-		if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			return r.Status().Update(ctx, r.s3UserClaim)
-		}); err != nil {
-			r.logger.Error(err, "failed to update s3 user claim")
-			return subreconciler.Requeue()
-		}
+	if err := r.updateStatus(ctx, status); err != nil {
+		r.logger.Error(err, "failed to update s3 user claim")
+		return subreconciler.Requeue()
+	}
+	if err := r.updateS3UserStatus(ctx); err != nil {
+		r.logger.Error(err, "failed to update s3 user status")
+		return subreconciler.Requeue()
 	}
 
 	return subreconciler.ContinueReconciling()
 }
-func (r *Reconciler) updateNamespaceQuotaStatusInclusive(ctx context.Context) (*ctrl.Result, error) {
+
+// updateS3UserClaimConditions persists the conditions gathered so far while keeping the rest of the status intact.
+// It's used when provisioning halts midway.
+func (r *reconcileRequest) updateS3UserClaimConditions(ctx context.Context) {
+	status := r.s3UserClaim.Status.DeepCopy()
+	status.ObservedGeneration = r.s3UserClaim.Generation
+	status.Conditions = r.conditions
+	if r.keyRotation != nil {
+		status.KeyRotation = r.keyRotation
+	}
+	if r.usage != nil {
+		status.Usage = r.usage
+	}
+
+	if err := r.updateStatus(ctx, *status); err != nil {
+		r.logger.Error(err, "failed to update s3 user claim conditions")
+		return
+	}
+	if err := r.updateS3UserStatus(ctx); err != nil {
+		r.logger.Error(err, "failed to update s3 user status")
+	}
+}
+
+func (r *reconcileRequest) updateStatus(ctx context.Context, status s3v1alpha1.S3UserClaimStatus) error {
+	if apiequality.Semantic.DeepEqual(r.s3UserClaim.Status, status) {
+		return nil
+	}
+	r.s3UserClaim.Status = status
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return r.Status().Update(ctx, r.s3UserClaim)
+	})
+}
+
+// updateS3UserStatus mirrors the readiness of the S3UserClaim to its S3User
+func (r *reconcileRequest) updateS3UserStatus(ctx context.Context) error {
+	s3User := &s3v1alpha1.S3User{}
+	switch err := r.Get(ctx, types.NamespacedName{Name: r.s3UserName}, s3User); {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	status := s3User.Status.DeepCopy()
+	status.ObservedGeneration = s3User.Generation
+	// A retained user which is claimed again is no longer retained
+	status.RetainedTime = nil
+	if r.usage != nil {
+		status.Usage = r.usage
+	}
+	if ready := meta.FindStatusCondition(r.conditions, consts.ConditionTypeReady); ready != nil {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               consts.ConditionTypeReady,
+			Status:             ready.Status,
+			Reason:             ready.Reason,
+			Message:            ready.Message,
+			ObservedGeneration: s3User.Generation,
+		})
+	}
+
+	if apiequality.Semantic.DeepEqual(s3User.Status, *status) {
+		return nil
+	}
+	s3User.Status = *status
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return r.Status().Update(ctx, s3User)
+	})
+}
+
+// setCondition sets the condition of a provisioning step regarding its error.
+// A failed step marks the S3UserClaim as not ready as well and is reported by a warning event.
+func (r *reconcileRequest) setCondition(conditionType string, err error) {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             consts.ConditionReasonSynced,
+		ObservedGeneration: r.s3UserClaim.Generation,
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = consts.ConditionReasonSyncFailed
+		condition.Message = err.Error()
+		r.setReadyCondition(metav1.ConditionFalse, consts.ConditionReasonProvisioningFailed,
+			fmt.Sprintf("%s: %s", conditionType, err.Error()))
+		r.recorder.Eventf(r.s3UserClaim, corev1.EventTypeWarning, consts.EventReasonSyncFailed, "%s: %s",
+			conditionType, err.Error())
+	}
+	meta.SetStatusCondition(&r.conditions, condition)
+}
+
+func (r *reconcileRequest) setReadyCondition(status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&r.conditions, metav1.Condition{
+		Type:               consts.ConditionTypeReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: r.s3UserClaim.Generation,
+	})
+}
+func (r *reconcileRequest) updateNamespaceQuotaStatusInclusive(ctx context.Context) (*ctrl.Result, error) {
 	return r.updateNamespaceQuotaStatus(ctx, true)
 }
 
-func (r *Reconciler) updateNamespaceQuotaStatusExclusive(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) updateNamespaceQuotaStatusExclusive(ctx context.Context) (*ctrl.Result, error) {
 	return r.updateNamespaceQuotaStatus(ctx, false)
 }
 
-func (r *Reconciler) updateNamespaceQuotaStatus(ctx context.Context, addCurrentQuota bool) (*ctrl.Result, error) {
+func (r *reconcileRequest) updateNamespaceQuotaStatus(ctx context.Context, addCurrentQuota bool) (*ctrl.Result, error) {
 	var err error
 	// sum up all quotas in the namespace
-	r.namespaceUsedQuota, err = s3v1alpha1.CalculateNamespaceUsedQuota(ctx, r.uncachedReader, r.s3UserClaim, r.s3UserClaimNamespace, addCurrentQuota)
+	r.namespaceUsedQuota, err = s3v1alpha1.CalculateNamespaceUsedQuota(ctx, r.uncachedReader,
+		s3v1alpha1.NewClassQuotas(r.uncachedReader), r.s3UserClaim, r.s3UserClaimNamespace, addCurrentQuota)
 	if err != nil {
 		r.logger.Error(err, "failed to calculate namespace used quota")
 		return subreconciler.Requeue()
 	}
+	// In the overcommit mode the hard limits are compared to the actual usage, so the usage is reported as used
+	if s3v1alpha1.QuotaEnforcement.IsOvercommit() {
+		s3UserClaim := r.s3UserClaim.DeepCopy()
+		s3UserClaim.Status.Usage = r.usage
+		r.namespaceUsedQuota, err = s3v1alpha1.CalculateNamespaceUsage(ctx, r.uncachedReader, s3UserClaim, r.s3UserClaimNamespace, addCurrentQuota)
+		if err != nil {
+			r.logger.Error(err, "failed to calculate namespace usage")
+			return subreconciler.Requeue()
+		}
+	}
 	// update the resource quota status used field
 	resourceQuotaList := &corev1.ResourceQuotaList{}
 	err = r.Client.List(ctx, resourceQuotaList, client.InNamespace(r.s3UserClaimNamespace))
@@ -291,6 +512,22 @@ func (r *Reconciler) updateNamespaceQuotaStatus(ctx context.Context, addCurrentQ
 	return subreconciler.ContinueReconciling()
 }
 
+// checkQuotaThresholds warns with events about the namespace and the team of the s3UserClaim when their consumption
+// reaches the warning threshold of their quota. Failing to check the thresholds doesn't affect the provisioning.
+func (r *reconcileRequest) checkQuotaThresholds(ctx context.Context) (*ctrl.Result, error) {
+	s3UserClaim := r.s3UserClaim.DeepCopy()
+	s3UserClaim.Status.Usage = r.usage
+	warnings, err := s3v1alpha1.CheckQuotaThresholds(ctx, r.Client, r.uncachedReader, s3UserClaim)
+	if err != nil {
+		r.logger.Error(err, "failed to check quota thresholds")
+		return subreconciler.ContinueReconciling()
+	}
+	for _, warning := range warnings {
+		r.recorder.Event(r.s3UserClaim, corev1.EventTypeWarning, consts.EventReasonQuotaThreshold, warning)
+	}
+	return subreconciler.ContinueReconciling()
+}
+
 func assignUsedQuotaToResourceStatus(resourceQuotaStatus *corev1.ResourceQuotaStatus, usedQuota *s3v1alpha1.UserQuota) {
 	if resourceQuotaStatus.Used == nil {
 		resourceQuotaStatus.Used = corev1.ResourceList{}
@@ -300,7 +537,7 @@ func assignUsedQuotaToResourceStatus(resourceQuotaStatus *corev1.ResourceQuotaSt
 	resourceQuotaStatus.Used[consts.ResourceNameS3MaxBuckets] = *resource.NewQuantity(usedQuota.MaxBuckets, resource.DecimalSI)
 }
 
-func (r *Reconciler) addCleanupFinalizer(ctx context.Context) (*ctrl.Result, error) {
+func (r *reconcileRequest) addCleanupFinalizer(ctx context.Context) (*ctrl.Result, error) {
 	if objUpdated := controllerutil.AddFinalizer(r.s3UserClaim, consts.S3UserClaimCleanupFinalizer); objUpdated {
 		if err := r.Update(ctx, r.s3UserClaim); err != nil {
 			r.logger.Error(err, "failed to update s3UserClaim")
@@ -311,16 +548,20 @@ func (r *Reconciler) addCleanupFinalizer(ctx context.Context) (*ctrl.Result, err
 }
 
 // ensureSecret ensures the passed secret exists and is controlled by r.s3UserClaim
-func (r *Reconciler) ensureSecret(ctx context.Context, secret *corev1.Secret) (*ctrl.Result, error) {
+func (r *reconcileRequest) ensureSecret(ctx context.Context, secret *corev1.Secret) (*ctrl.Result, error) {
 	existingSecret := &corev1.Secret{}
 	switch err := r.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, existingSecret); {
 	case apierrors.IsNotFound(err):
 		if err := r.Create(ctx, secret); err != nil {
 			r.logger.Error(err, "failed to create secret", "name", secret.Name)
+			r.setCondition(consts.ConditionTypeSecretsSynced, fmt.Errorf("failed to create secret %s, %w", secret.Name, err))
 			return subreconciler.Requeue()
 		}
+		r.recorder.Eventf(r.s3UserClaim, corev1.EventTypeNormal, consts.EventReasonSecretWritten,
+			"Created secret %s", secret.Name)
 	case err != nil:
 		r.logger.Error(err, "failed to get secret", "name", secret.Name)
+		r.setCondition(consts.ConditionTypeSecretsSynced, fmt.Errorf("failed to get secret %s, %w", secret.Name, err))
 		return subreconciler.Requeue()
 	default:
 		if !apiequality.Semantic.DeepEqual(existingSecret.Data, secret.Data) ||
@@ -328,19 +569,24 @@ func (r *Reconciler) ensureSecret(ctx context.Context, secret *corev1.Secret) (*
 			existingSecret.Data = secret.Data
 			if err := ctrl.SetControllerReference(r.s3UserClaim, existingSecret, r.scheme); err != nil {
 				r.logger.Error(err, "failed to set controller reference", "secret name", secret.Name)
+				r.setCondition(consts.ConditionTypeSecretsSynced,
+					fmt.Errorf("failed to set controller reference of secret %s, %w", secret.Name, err))
 				return subreconciler.Requeue()
 			}
 			if err := r.Update(ctx, existingSecret); err != nil {
 				r.logger.Error(err, "failed to update secret", "name", secret.Name)
+				r.setCondition(consts.ConditionTypeSecretsSynced, fmt.Errorf("failed to update secret %s, %w", secret.Name, err))
 				return subreconciler.Requeue()
 			}
+			r.recorder.Eventf(r.s3UserClaim, corev1.EventTypeNormal, consts.EventReasonSecretWritten,
+				"Updated secret %s", secret.Name)
 		}
 	}
 
 	return subreconciler.ContinueReconciling()
 }
 
-func (r *Reconciler) assembleS3User() (*s3v1alpha1.S3User, error) {
+func (r *reconcileRequest) assembleS3User() (*s3v1alpha1.S3User, error) {
 	claimRef, err := reference.GetReference(r.scheme, r.s3UserClaim)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create claim reference, %w", err)
@@ -351,13 +597,15 @@ func (r *Reconciler) assembleS3User() (*s3v1alpha1.S3User, error) {
 			Name: r.s3UserName,
 		},
 		Spec: s3v1alpha1.S3UserSpec{
-			S3UserClass: r.s3UserClass,
+			S3UserClass: r.s3UserClass.Name,
 			Quota: &s3v1alpha1.UserQuota{
-				MaxSize:    r.s3UserClaim.Spec.Quota.MaxSize,
-				MaxObjects: r.s3UserClaim.Spec.Quota.MaxObjects,
-				MaxBuckets: r.s3UserClaim.Spec.Quota.MaxBuckets,
+				MaxSize:    r.quota.MaxSize,
+				MaxObjects: r.quota.MaxObjects,
+				MaxBuckets: r.quota.MaxBuckets,
 			},
-			ClaimRef: claimRef,
+			ClaimRef:       claimRef,
+			CephUserID:     r.cephUserFullId,
+			DeletionPolicy: r.deletionPolicy,
 		},
 	}
 
@@ -366,10 +614,10 @@ func (r *Reconciler) assembleS3User() (*s3v1alpha1.S3User, error) {
 
 // assembleCephUserSecret tries to find a key for the given userName and assembles a secret
 // with accessKey and secretKey of the found key
-func (r *Reconciler) assembleCephUserSecret(userName, secretName string) (*corev1.Secret, error) {
+func (r *reconcileRequest) assembleCephUserSecret(userName, secretName string) (*corev1.Secret, error) {
 	var existingKey *admin.UserKeySpec
 	for _, key := range r.cephUser.Keys {
-		if key.User == userName {
+		if key.User == userName && !r.isRetiredKey(key.AccessKey) {
 			existingKey = &key
 			break
 		}
@@ -397,7 +645,7 @@ func (r *Reconciler) assembleCephUserSecret(userName, secretName string) (*corev
 	return secret, nil
 }
 
-func (r *Reconciler) generateSubuserAccess(desiredSubusers []string,
+func (r *reconcileRequest) generateSubuserAccess(desiredSubusers []string,
 	currentSubusers []admin.SubuserSpec) map[string]string {
 	// Create a map to move all spec and ceph subusers to it
 	subuserFullIdAccess := make(map[string]string)
@@ -424,7 +672,7 @@ func (r *Reconciler) generateSubuserAccess(desiredSubusers []string,
 	return subuserFullIdAccess
 }
 
-func (r *Reconciler) generateSubuser(ctx context.Context, cephUserFullId string,
+func (r *reconcileRequest) generateSubuser(ctx context.Context, cephUserFullId string,
 	desiredSubuser admin.SubuserSpec) error {
 	r.logger.Info(fmt.Sprintf("Create subuser: %s", desiredSubuser.Name))
 	if err := r.rgwClient.CreateSubuser(ctx, admin.User{ID: cephUserFullId}, desiredSubuser); err != nil {
@@ -434,7 +682,7 @@ func (r *Reconciler) generateSubuser(ctx context.Context, cephUserFullId string,
 	return nil
 }
 
-func (r *Reconciler) removeSubuserAndSecret(ctx context.Context, cephUserFullId string,
+func (r *reconcileRequest) removeSubuserAndSecret(ctx context.Context, cephUserFullId string,
 	subuserToRemove admin.SubuserSpec) error {
 	r.logger.Info(fmt.Sprintf("Remove subuser: %s", subuserToRemove.Name))
 	if err := r.rgwClient.RemoveSubuser(ctx, admin.User{ID: cephUserFullId},
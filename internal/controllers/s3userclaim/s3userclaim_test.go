@@ -21,16 +21,22 @@ import (
 	goerrors "errors"
 	"fmt"
 	"regexp"
+	"sync"
+	"time"
 
 	"github.com/ceph/go-ceph/rgw/admin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	openshiftquota "github.com/openshift/api/quota/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/reference"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
 	"github.com/snapp-incubator/ceph-s3-operator/internal/config"
@@ -237,6 +243,478 @@ var _ = Describe("S3UserClaim Controller", func() {
 				g.Expect(s3UserClaim.Status.Quota).NotTo(BeNil())
 				g.Expect(*s3UserClaim.Status.Quota).To(Equal(*s3UserClaim.Spec.Quota))
 				g.Expect(s3UserClaim.Status.S3UserName).To(Equal(s3UserName))
+				g.Expect(s3UserClaim.Status.ObservedGeneration).To(Equal(s3UserClaim.Generation))
+				g.Expect(meta.IsStatusConditionTrue(s3UserClaim.Status.Conditions, consts.ConditionTypeReady)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionTrue(s3UserClaim.Status.Conditions, consts.ConditionTypeCephUserSynced)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionTrue(s3UserClaim.Status.Conditions, consts.ConditionTypeSecretsSynced)).To(BeTrue())
+			}).Should(Succeed())
+		})
+
+		It("Should rotate the keys when the rotate-keys annotation changes", func() {
+			var oldAccessKey string
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(
+					ctx,
+					types.NamespacedName{Name: adminSecretName, Namespace: s3UserClaimNamespace},
+					adminSecret,
+				)).To(Succeed())
+				oldAccessKey = string(adminSecret.Data[consts.DataKeyAccessKey])
+				g.Expect(oldAccessKey).NotTo(BeEmpty())
+			}).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(
+					ctx,
+					types.NamespacedName{Name: s3UserClaimName, Namespace: s3UserClaimNamespace},
+					s3UserClaim,
+				)).To(Succeed())
+				s3UserClaim.Annotations = map[string]string{consts.AnnotationRotateKeys: "1"}
+				g.Expect(k8sClient.Update(ctx, s3UserClaim)).To(Succeed())
+			}).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(
+					ctx,
+					types.NamespacedName{Name: adminSecretName, Namespace: s3UserClaimNamespace},
+					adminSecret,
+				)).To(Succeed())
+				newAccessKey := string(adminSecret.Data[consts.DataKeyAccessKey])
+				g.Expect(newAccessKey).NotTo(Equal(oldAccessKey))
+
+				g.Expect(k8sClient.Get(
+					ctx,
+					types.NamespacedName{Name: s3UserClaimName, Namespace: s3UserClaimNamespace},
+					s3UserClaim,
+				)).To(Succeed())
+				g.Expect(s3UserClaim.Status.KeyRotation).NotTo(BeNil())
+				g.Expect(s3UserClaim.Status.KeyRotation.LastRotationTime).NotTo(BeNil())
+				g.Expect(s3UserClaim.Status.KeyRotation.LastRotationTrigger).To(Equal("1"))
+				g.Expect(s3UserClaim.Status.KeyRotation.RetiredKeys).To(ContainElement(
+					HaveField("AccessKey", oldAccessKey)))
+
+				// The old key stays valid during the grace period
+				gotCephUser, err := rgwClient.GetUser(ctx, cephUser)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(gotCephUser.Keys).To(ContainElement(HaveField("AccessKey", oldAccessKey)))
+				g.Expect(gotCephUser.Keys).To(ContainElement(HaveField("AccessKey", newAccessKey)))
+			}).Should(Succeed())
+		})
+
+		It("Should resume a rotation whose keys are pending", func() {
+			const pendingAccessKey = "PENDINGROTATIONKEY01"
+			var oldAccessKey string
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(
+					ctx,
+					types.NamespacedName{Name: adminSecretName, Namespace: s3UserClaimNamespace},
+					adminSecret,
+				)).To(Succeed())
+				oldAccessKey = string(adminSecret.Data[consts.DataKeyAccessKey])
+				g.Expect(oldAccessKey).NotTo(BeEmpty())
+			}).Should(Succeed())
+
+			By("Expect to record a pending key as an interrupted rotation does")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(s3UserClaim), s3UserClaim)).To(Succeed())
+				s3UserClaim.Status.KeyRotation = &s3v1alpha1.KeyRotationStatus{
+					PendingKeys: []s3v1alpha1.PendingKey{{User: cephUser.ID, AccessKey: pendingAccessKey}},
+				}
+				g.Expect(k8sClient.Status().Update(ctx, s3UserClaim)).To(Succeed())
+			}).Should(Succeed())
+
+			By("Expect the pending key to be created and to replace the previous key")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(
+					ctx,
+					types.NamespacedName{Name: adminSecretName, Namespace: s3UserClaimNamespace},
+					adminSecret,
+				)).To(Succeed())
+				g.Expect(string(adminSecret.Data[consts.DataKeyAccessKey])).To(Equal(pendingAccessKey))
+
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(s3UserClaim), s3UserClaim)).To(Succeed())
+				g.Expect(s3UserClaim.Status.KeyRotation).NotTo(BeNil())
+				g.Expect(s3UserClaim.Status.KeyRotation.PendingKeys).To(BeEmpty())
+				g.Expect(s3UserClaim.Status.KeyRotation.RetiredKeys).To(ContainElement(
+					HaveField("AccessKey", oldAccessKey)))
+
+				gotCephUser, err := rgwClient.GetUser(ctx, cephUser)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(gotCephUser.Keys).To(ContainElement(HaveField("AccessKey", pendingAccessKey)))
+			}).Should(Succeed())
+		})
+
+		It("Should report the usage of the Ceph user", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(
+					ctx,
+					types.NamespacedName{Name: s3UserClaimName, Namespace: s3UserClaimNamespace},
+					s3UserClaim,
+				)).To(Succeed())
+				g.Expect(s3UserClaim.Status.Usage).NotTo(BeNil())
+				g.Expect(s3UserClaim.Status.Usage.LastUpdateTime).NotTo(BeNil())
+				g.Expect(s3UserClaim.Status.Usage.Buckets).To(BeZero())
+				g.Expect(s3UserClaim.Status.Usage.SizePercentage).To(BeZero())
+
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: s3UserName}, s3User)).To(Succeed())
+				g.Expect(s3User.Status.Usage).To(Equal(s3UserClaim.Status.Usage))
+			}).Should(Succeed())
+		})
+
+		It("Should record the provisioning as events of the S3UserClaim", func() {
+			Eventually(func(g Gomega) {
+				eventList := &v1.EventList{}
+				g.Expect(k8sClient.List(ctx, eventList, client.InNamespace(s3UserClaimNamespace),
+					client.MatchingFields{"involvedObject.name": s3UserClaimName})).To(Succeed())
+
+				var reasons []string
+				for _, event := range eventList.Items {
+					reasons = append(reasons, event.Reason)
+				}
+				g.Expect(reasons).To(ContainElements(consts.EventReasonUserCreated, consts.EventReasonSecretWritten))
+			}).Should(Succeed())
+		})
+
+		It("Should mark the S3User as ready", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: s3UserName}, s3User)).To(Succeed())
+
+				g.Expect(s3User.Status.ObservedGeneration).To(Equal(s3User.Generation))
+				g.Expect(meta.IsStatusConditionTrue(s3User.Status.Conditions, consts.ConditionTypeReady)).To(BeTrue())
+			}).Should(Succeed())
+		})
+	})
+
+	Context("When creating many S3UserClaims in parallel", func() {
+		const parallelClaims = 10
+		var s3UserClaims []*s3v1alpha1.S3UserClaim
+
+		BeforeEach(func() {
+			s3UserClaims = nil
+			for i := 0; i < parallelClaims; i++ {
+				claim := getS3UserClaim()
+				claim.Name = fmt.Sprintf("%s-%d", s3UserClaimName, i)
+				claim.Spec.AdminSecret = fmt.Sprintf("%s-%d", adminSecretName, i)
+				claim.Spec.ReadonlySecret = fmt.Sprintf("%s-%d", readonlySecretName, i)
+				s3UserClaims = append(s3UserClaims, claim)
+			}
+
+			var wg sync.WaitGroup
+			for _, claim := range s3UserClaims {
+				wg.Add(1)
+				go func(claim *s3v1alpha1.S3UserClaim) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					Expect(k8sClient.Create(ctx, claim)).To(Succeed())
+				}(claim)
+			}
+			wg.Wait()
+		})
+
+		AfterEach(func() {
+			for _, claim := range s3UserClaims {
+				Expect(k8sClient.Delete(ctx, claim)).To(Succeed())
+			}
+
+			By("Expect the Ceph users and secrets are cleaned up")
+			Eventually(func(g Gomega) {
+				for _, claim := range s3UserClaims {
+					for _, secretName := range []string{claim.Spec.AdminSecret, claim.Spec.ReadonlySecret} {
+						err := k8sClient.Delete(ctx, &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+							Name:      secretName,
+							Namespace: s3UserClaimNamespace,
+						}})
+						g.Expect(err == nil || apierrors.IsNotFound(err)).To(BeTrue())
+					}
+
+					_, err := rgwClient.GetUser(ctx, admin.User{ID: fmt.Sprintf(
+						"%s__%s$%s",
+						k8sNameSpecialChars.ReplaceAllString(cfg.ClusterName, "_"),
+						k8sNameSpecialChars.ReplaceAllString(s3UserClaimNamespace, "_"),
+						claim.Name,
+					)})
+					g.Expect(goerrors.Is(err, admin.ErrNoSuchUser)).To(BeTrue())
+				}
+			}, 30*time.Second).Should(Succeed())
+		})
+
+		It("Should provision all of them", func() {
+			Eventually(func(g Gomega) {
+				for _, claim := range s3UserClaims {
+					gotClaim := &s3v1alpha1.S3UserClaim{}
+					g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(claim), gotClaim)).To(Succeed())
+					g.Expect(meta.IsStatusConditionTrue(gotClaim.Status.Conditions, consts.ConditionTypeReady)).To(BeTrue())
+					g.Expect(gotClaim.Status.S3UserName).To(Equal(fmt.Sprintf("%s.%s", s3UserClaimNamespace, claim.Name)))
+
+					adminSecret := &v1.Secret{}
+					g.Expect(k8sClient.Get(
+						ctx,
+						types.NamespacedName{Name: claim.Spec.AdminSecret, Namespace: s3UserClaimNamespace},
+						adminSecret,
+					)).To(Succeed())
+
+					gotCephUser, err := rgwClient.GetUser(ctx, admin.User{ID: fmt.Sprintf(
+						"%s__%s$%s",
+						k8sNameSpecialChars.ReplaceAllString(cfg.ClusterName, "_"),
+						k8sNameSpecialChars.ReplaceAllString(s3UserClaimNamespace, "_"),
+						claim.Name,
+					)})
+					g.Expect(err).NotTo(HaveOccurred())
+					g.Expect(gotCephUser.Keys).To(ContainElement(admin.UserKeySpec{
+						User:      gotCephUser.ID,
+						AccessKey: string(adminSecret.Data[consts.DataKeyAccessKey]),
+						SecretKey: string(adminSecret.Data[consts.DataKeySecretKey]),
+					}))
+				}
+			}, 30*time.Second).Should(Succeed())
+		})
+	})
+
+	Context("When creating an S3UserClaim of an S3UserClass", func() {
+		const s3UserClassName = "second-cluster"
+		var (
+			s3UserClassObj   *s3v1alpha1.S3UserClass
+			classAdminSecret *v1.Secret
+			classCephUser    = admin.User{
+				ID: fmt.Sprintf(
+					"%s__%s$%s",
+					k8sNameSpecialChars.ReplaceAllString(s3UserClassName, "_"),
+					k8sNameSpecialChars.ReplaceAllString(s3UserClaimNamespace, "_"),
+					s3UserClaimName,
+				),
+			}
+			defaultQuota = s3v1alpha1.UserQuota{
+				MaxSize:    resource.MustParse("5k"),
+				MaxObjects: resource.MustParse("6k"),
+				MaxBuckets: 7,
+			}
+		)
+
+		BeforeEach(func() {
+			// The class points at the same RGW as the config, only the cluster name, i.e. the tenant prefix, differs
+			classAdminSecret = &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "second-cluster-rgw-admin",
+					Namespace: s3UserClaimNamespace,
+				},
+				StringData: map[string]string{
+					consts.DataKeyAccessKey: cfg.Rgw.AccessKey,
+					consts.DataKeySecretKey: cfg.Rgw.SecretKey,
+				},
+			}
+			Expect(k8sClient.Create(ctx, classAdminSecret)).To(Succeed())
+
+			s3UserClassObj = &s3v1alpha1.S3UserClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: s3UserClassName,
+				},
+				Spec: s3v1alpha1.S3UserClassSpec{
+					Endpoint: cfg.Rgw.Endpoint,
+					AdminSecretRef: v1.SecretReference{
+						Name:      classAdminSecret.Name,
+						Namespace: classAdminSecret.Namespace,
+					},
+					ClusterName:  s3UserClassName,
+					DefaultQuota: &defaultQuota,
+				},
+			}
+			Expect(k8sClient.Create(ctx, s3UserClassObj)).To(Succeed())
+
+			s3UserClaim = getS3UserClaim()
+			s3UserClaim.Spec.S3UserClass = s3UserClassName
+			s3UserClaim.Spec.Quota = nil
+			Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+			s3User = &s3v1alpha1.S3User{}
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, s3UserClaim)).To(Succeed())
+			Eventually(func(g Gomega) {
+				_, err := rgwClient.GetUser(ctx, classCephUser)
+				g.Expect(goerrors.Is(err, admin.ErrNoSuchUser)).To(BeTrue())
+
+				for _, secretName := range []string{adminSecretName, readonlySecretName} {
+					err := k8sClient.Delete(ctx, &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+						Name:      secretName,
+						Namespace: s3UserClaimNamespace,
+					}})
+					g.Expect(err == nil || apierrors.IsNotFound(err)).To(BeTrue())
+				}
+			}).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, s3UserClassObj)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, classAdminSecret)).To(Succeed())
+		})
+
+		It("Should create the Ceph user in the tenant of the class with the default quota of the class", func() {
+			Eventually(func(g Gomega) {
+				gotUser, err := rgwClient.GetUser(ctx, classCephUser)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(*gotUser.MaxBuckets).To(Equal(int(defaultQuota.MaxBuckets)))
+				g.Expect(*gotUser.UserQuota.MaxSize).To(Equal(defaultQuota.MaxSize.Value()))
+				g.Expect(*gotUser.UserQuota.MaxObjects).To(Equal(defaultQuota.MaxObjects.Value()))
+
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: s3UserName}, s3User)).To(Succeed())
+				g.Expect(s3User.Spec.S3UserClass).To(Equal(s3UserClassName))
+			}).Should(Succeed())
+		})
+	})
+
+	Context("When adopting an existing Ceph user", func() {
+		const secondS3UserClaimName = "second-s3userclaim"
+		var (
+			existingCephUser   = admin.User{ID: "legacy$app", DisplayName: "legacy application"}
+			adoptedS3UserName  = generateAdoptedS3UserName(s3UserClass, existingCephUser.ID)
+			secondS3UserClaim  *s3v1alpha1.S3UserClaim
+			existingAccessKey  string
+			secondSecretNames  = []string{"second-admin-secret", "second-readonly-secret"}
+			adoptedSecretNames = []string{adminSecretName, readonlySecretName}
+		)
+
+		BeforeEach(func() {
+			createdUser, err := rgwClient.CreateUser(ctx, existingCephUser)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdUser.Keys).NotTo(BeEmpty())
+			existingAccessKey = createdUser.Keys[0].AccessKey
+
+			s3UserClaim = getS3UserClaim()
+			s3UserClaim.Spec.ExistingUser = existingCephUser.ID
+			Expect(k8sClient.Create(ctx, s3UserClaim)).To(Succeed())
+			s3User = &s3v1alpha1.S3User{}
+
+			secondS3UserClaim = getS3UserClaim()
+			secondS3UserClaim.Name = secondS3UserClaimName
+			secondS3UserClaim.Spec.AdminSecret = secondSecretNames[0]
+			secondS3UserClaim.Spec.ReadonlySecret = secondSecretNames[1]
+			secondS3UserClaim.Spec.ExistingUser = existingCephUser.ID
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, secondS3UserClaim))).To(Succeed())
+			Expect(k8sClient.Delete(ctx, s3UserClaim)).To(Succeed())
+
+			By("Expect the adopted user to be retained and cleaned up manually")
+			Eventually(func(g Gomega) {
+				g.Expect(apierrors.IsNotFound(
+					k8sClient.Get(ctx, client.ObjectKeyFromObject(s3UserClaim), &s3v1alpha1.S3UserClaim{}),
+				)).To(BeTrue())
+				_, err := rgwClient.GetUser(ctx, existingCephUser)
+				g.Expect(err).NotTo(HaveOccurred())
+			}).Should(Succeed())
+			Expect(rgwClient.RemoveUser(ctx, admin.User{ID: existingCephUser.ID, PurgeData: pointer.Int(1)})).To(Succeed())
+			Eventually(func(g Gomega) {
+				retainedS3User := &s3v1alpha1.S3User{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: adoptedS3UserName}, retainedS3User)
+				if apierrors.IsNotFound(err) {
+					return
+				}
+				g.Expect(err).NotTo(HaveOccurred())
+				retainedS3User.Spec.DeletionPolicy = ""
+				g.Expect(k8sClient.Update(ctx, retainedS3User)).To(Succeed())
+				g.Expect(apierrors.IsNotFound(
+					k8sClient.Get(ctx, types.NamespacedName{Name: adoptedS3UserName}, retainedS3User),
+				)).To(BeTrue())
+			}).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				for _, secretName := range append(adoptedSecretNames, secondSecretNames...) {
+					err := k8sClient.Delete(ctx, &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+						Name:      secretName,
+						Namespace: s3UserClaimNamespace,
+					}})
+					g.Expect(client.IgnoreNotFound(err)).To(Succeed())
+				}
+			}).Should(Succeed())
+		})
+
+		It("Should import the existing keys of the user", func() {
+			Eventually(func(g Gomega) {
+				secret := &v1.Secret{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Name:      adminSecretName,
+					Namespace: s3UserClaimNamespace,
+				}, secret)).To(Succeed())
+				g.Expect(string(secret.Data[consts.DataKeyAccessKey])).To(Equal(existingAccessKey))
+
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: adoptedS3UserName}, s3User)).To(Succeed())
+				g.Expect(s3User.Spec.CephUserID).To(Equal(existingCephUser.ID))
+				g.Expect(s3User.Spec.DeletionPolicy).To(Equal(consts.UserDeletionPolicyRetain))
+			}).Should(Succeed())
+
+			gotUser, err := rgwClient.GetUser(ctx, existingCephUser)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gotUser.DisplayName).To(Equal(existingCephUser.DisplayName))
+		})
+
+		It("Should refuse adopting a user whose subusers aren't in the claim", func() {
+			legacyCephUser := admin.User{ID: "legacy$worker", DisplayName: "legacy worker"}
+			_, err := rgwClient.CreateUser(ctx, legacyCephUser)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rgwClient.CreateSubuser(ctx, legacyCephUser, admin.SubuserSpec{
+				Name:    "legacy$worker:ci",
+				Access:  admin.SubuserAccessNone,
+				KeyType: pointer.String(consts.CephKeyTypeS3),
+			})).To(Succeed())
+
+			secondS3UserClaim.Spec.ExistingUser = legacyCephUser.ID
+			secondS3UserClaim.Spec.DeletionPolicy = consts.UserDeletionPolicyDelete
+			Expect(k8sClient.Create(ctx, secondS3UserClaim)).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(secondS3UserClaim), secondS3UserClaim)).To(Succeed())
+				condition := meta.FindStatusCondition(secondS3UserClaim.Status.Conditions,
+					consts.ConditionTypeCephUserSynced)
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Message).To(ContainSubstring(consts.ErrCephUserNotAdoptable.Error()))
+			}).Should(Succeed())
+			gotUser, err := rgwClient.GetUser(ctx, legacyCephUser)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gotUser.Subusers).To(HaveLen(1))
+
+			By("Expect to adopt the user once its subusers are in the claim")
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(secondS3UserClaim), secondS3UserClaim)).To(Succeed())
+				secondS3UserClaim.Spec.Subusers = []s3v1alpha1.Subuser{"ci"}
+				g.Expect(k8sClient.Update(ctx, secondS3UserClaim)).To(Succeed())
+			}).Should(Succeed())
+			Eventually(func(g Gomega) {
+				secondS3User := &s3v1alpha1.S3User{}
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{
+					Name: generateAdoptedS3UserName(s3UserClass, legacyCephUser.ID),
+				}, secondS3User)).To(Succeed())
+				g.Expect(secondS3User.Spec.CephUserID).To(Equal(legacyCephUser.ID))
+
+				gotUser, err := rgwClient.GetUser(ctx, legacyCephUser)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(gotUser.Subusers).To(HaveLen(2))
+			}).Should(Succeed())
+
+			Expect(k8sClient.Delete(ctx, secondS3UserClaim)).To(Succeed())
+			Eventually(func(g Gomega) {
+				_, err := rgwClient.GetUser(ctx, legacyCephUser)
+				g.Expect(goerrors.Is(err, admin.ErrNoSuchUser)).To(BeTrue())
+			}).Should(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &v1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      generateSubuserSecretName(secondS3UserClaimName, "ci"),
+				Namespace: s3UserClaimNamespace,
+			}}))).To(Succeed())
+		})
+
+		It("Should refuse adopting a user which is bound to another S3User", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: adoptedS3UserName}, s3User)).To(Succeed())
+			}).Should(Succeed())
+			Expect(k8sClient.Create(ctx, secondS3UserClaim)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(secondS3UserClaim), secondS3UserClaim)).To(Succeed())
+				condition := meta.FindStatusCondition(secondS3UserClaim.Status.Conditions,
+					consts.ConditionTypeCephUserSynced)
+				g.Expect(condition).NotTo(BeNil())
+				g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(condition.Message).To(ContainSubstring(consts.ErrCephUserAlreadyBound.Error()))
+			}).Should(Succeed())
+			Consistently(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: adoptedS3UserName}, s3User)).To(Succeed())
+				g.Expect(s3User.Spec.ClaimRef.Name).To(Equal(s3UserClaimName))
 			}).Should(Succeed())
 		})
 	})
@@ -302,6 +780,53 @@ var _ = Describe("S3UserClaim Controller", func() {
 			}).Should(Succeed())
 		})
 
+		createBucket := func(bucketName string) {
+			gotCephUser, err := rgwClient.GetUser(ctx, cephUser)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(gotCephUser.Keys)).To(Equal(2))
+			var s3Keys admin.UserKeySpec
+			if gotCephUser.Keys[0].User == cephUser.ID {
+				s3Keys = gotCephUser.Keys[0]
+			} else if gotCephUser.Keys[1].User == cephUser.ID {
+				s3Keys = gotCephUser.Keys[1]
+			} else {
+				Fail("failed to find the expected ceph user")
+			}
+
+			s3Agent, err := s3_agent.NewS3Agent(s3Keys.AccessKey, s3Keys.SecretKey, cfg.Rgw.Endpoint, cfg.Rgw.Region,
+				nil, cfg.Rgw.MaxRetries, true)
+			Expect(err).To(BeNil())
+			Expect(s3Agent.CreateBucket(bucketName)).To(Succeed())
+		}
+
+		setDeletionPolicy := func(deletionPolicy string) {
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(s3UserClaim), s3UserClaim)).To(Succeed())
+				s3UserClaim.Spec.DeletionPolicy = deletionPolicy
+				g.Expect(k8sClient.Update(ctx, s3UserClaim)).To(Succeed())
+			}).Should(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: s3User.Name}, s3User)).To(Succeed())
+				g.Expect(s3User.Spec.DeletionPolicy).To(Equal(deletionPolicy))
+			}).Should(Succeed())
+		}
+
+		expectCephUserWithBucket := func(bucketName string) {
+			Consistently(func(g Gomega) {
+				_, err := rgwClient.GetUser(ctx, cephUser)
+				g.Expect(err).NotTo(HaveOccurred())
+				buckets, err := rgwClient.ListUsersBuckets(ctx, cephUser.ID)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(buckets).To(ContainElement(bucketName))
+			}).Should(Succeed())
+		}
+
+		removeKeptCephUser := func() {
+			Expect(k8sClient.Delete(ctx, adminSecret)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, readonlySecret)).To(Succeed())
+			Expect(rgwClient.RemoveUser(ctx, admin.User{ID: cephUser.ID, PurgeData: pointer.Int(1)})).To(Succeed())
+		}
+
 		It("Should remove the Ceph user without buckets", func() {
 			By("Expect to delete the S3UserClaim successfully")
 			Eventually(func(g Gomega) {
@@ -323,21 +848,7 @@ var _ = Describe("S3UserClaim Controller", func() {
 		})
 
 		It("Should remove the Ceph user with buckets", func() {
-			gotCephUser, err := rgwClient.GetUser(ctx, cephUser)
-			Expect(err).NotTo(HaveOccurred())
-			Expect(len(gotCephUser.Keys)).To(Equal(2))
-			var s3Keys admin.UserKeySpec
-			if gotCephUser.Keys[0].User == cephUser.ID {
-				s3Keys = gotCephUser.Keys[0]
-			} else if gotCephUser.Keys[1].User == cephUser.ID {
-				s3Keys = gotCephUser.Keys[1]
-			} else {
-				Fail("failed to find the expected ceph user")
-			}
-
-			s3Agent, err := s3_agent.NewS3Agent(s3Keys.AccessKey, s3Keys.SecretKey, cfg.Rgw.Endpoint, true)
-			Expect(err).To(BeNil())
-			Expect(s3Agent.CreateBucket("test-bucket")).To(Succeed())
+			createBucket("test-bucket")
 
 			By("Expect to delete the S3UserClaim successfully")
 			Eventually(func(g Gomega) {
@@ -357,5 +868,208 @@ var _ = Describe("S3UserClaim Controller", func() {
 				g.Expect(goerrors.Is(err, admin.ErrNoSuchUser)).To(BeTrue())
 			}).Should(Succeed())
 		})
+
+		It("Should keep the Ceph user and the S3User with the Retain deletion policy", func() {
+			By("Expect to set the Retain deletion policy and delete the S3UserClaim successfully")
+			setDeletionPolicy(consts.UserDeletionPolicyRetain)
+			Expect(k8sClient.Delete(ctx, s3UserClaim)).To(Succeed())
+
+			By("Expect the S3User to record the retained Ceph user")
+			Eventually(func(g Gomega) {
+				g.Expect(apierrors.IsNotFound(
+					k8sClient.Get(ctx, client.ObjectKeyFromObject(s3UserClaim), &s3v1alpha1.S3UserClaim{}),
+				)).To(BeTrue())
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: s3User.Name}, s3User)).To(Succeed())
+				g.Expect(s3User.Spec.CephUserID).To(Equal(cephUser.ID))
+				g.Expect(s3User.Status.RetainedTime).NotTo(BeNil())
+			}).Should(Succeed())
+			Consistently(func(g Gomega) {
+				_, err := rgwClient.GetUser(ctx, cephUser)
+				g.Expect(err).NotTo(HaveOccurred())
+			}).Should(Succeed())
+
+			By("Expect to clean up the retained user manually")
+			Expect(k8sClient.Delete(ctx, adminSecret)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, readonlySecret)).To(Succeed())
+			Expect(rgwClient.RemoveUser(ctx, admin.User{ID: cephUser.ID, PurgeData: pointer.Int(1)})).To(Succeed())
+			s3User.Spec.DeletionPolicy = ""
+			Expect(k8sClient.Update(ctx, s3User)).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(
+					apierrors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: s3User.Name}, s3User)),
+				).To(BeTrue())
+			}).Should(Succeed())
+		})
+
+		It("Should keep the Ceph user and its buckets with the Orphan deletion policy", func() {
+			By("Expect to set the Orphan deletion policy and delete the S3UserClaim successfully")
+			setDeletionPolicy(consts.UserDeletionPolicyOrphan)
+			createBucket("orphan-test-bucket")
+			Expect(k8sClient.Delete(ctx, s3UserClaim)).To(Succeed())
+
+			By("Expect the S3UserClaim and the S3User to be removed")
+			Eventually(func(g Gomega) {
+				g.Expect(apierrors.IsNotFound(
+					k8sClient.Get(ctx, client.ObjectKeyFromObject(s3UserClaim), &s3v1alpha1.S3UserClaim{}),
+				)).To(BeTrue())
+				g.Expect(
+					apierrors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: s3User.Name}, s3User)),
+				).To(BeTrue())
+			}).Should(Succeed())
+
+			By("Expect the Ceph user and its bucket to be kept")
+			expectCephUserWithBucket("orphan-test-bucket")
+
+			By("Expect to clean up the orphaned user manually")
+			removeKeptCephUser()
+		})
+
+		It("Should keep the retained Ceph user and its buckets when the S3User is removed", func() {
+			By("Expect to set the Retain deletion policy and delete the S3UserClaim successfully")
+			setDeletionPolicy(consts.UserDeletionPolicyRetain)
+			createBucket("retain-test-bucket")
+			Expect(k8sClient.Delete(ctx, s3UserClaim)).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: s3User.Name}, s3User)).To(Succeed())
+				g.Expect(s3User.Status.RetainedTime).NotTo(BeNil())
+			}).Should(Succeed())
+
+			By("Expect to delete the retained S3User successfully")
+			Expect(k8sClient.Delete(ctx, s3User)).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(
+					apierrors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: s3User.Name}, s3User)),
+				).To(BeTrue())
+			}).Should(Succeed())
+
+			By("Expect the Ceph user and its bucket to be kept")
+			expectCephUserWithBucket("retain-test-bucket")
+
+			By("Expect to clean up the retained user manually")
+			removeKeptCephUser()
+		})
+	})
+
+	Context("When the namespace of an S3UserClaim has a ClusterResourceQuota", func() {
+		const (
+			team           = "team-quota-test"
+			teamNamespace  = "team-quota-test-a"
+			otherNamespace = "team-quota-test-b"
+			staleNamespace = "team-quota-test-stale"
+		)
+		var teamQuotaClaim *s3v1alpha1.S3UserClaim
+
+		getClusterQuota := func(g Gomega) *openshiftquota.ClusterResourceQuota {
+			clusterQuota := &openshiftquota.ClusterResourceQuota{}
+			g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: team}, clusterQuota)).To(Succeed())
+			return clusterQuota
+		}
+		namespaceStatus := func(g Gomega, clusterQuota *openshiftquota.ClusterResourceQuota,
+			namespace string) v1.ResourceQuotaStatus {
+			for _, namespaceStatus := range clusterQuota.Status.Namespaces {
+				if namespaceStatus.Namespace == namespace {
+					return namespaceStatus.Status
+				}
+			}
+			g.Expect(namespace).To(BeEmpty(), "namespace isn't in the status of the ClusterResourceQuota")
+			return v1.ResourceQuotaStatus{}
+		}
+		expectUsed := func(g Gomega, used v1.ResourceList, maxSize, maxObjects resource.Quantity, maxBuckets int64) {
+			g.Expect(used).To(HaveKey(consts.ResourceNameS3MaxSize))
+			g.Expect(used).To(HaveKey(consts.ResourceNameS3MaxObjects))
+			g.Expect(used).To(HaveKey(consts.ResourceNameS3MaxBuckets))
+			usedMaxSize, usedMaxObjects := used[consts.ResourceNameS3MaxSize], used[consts.ResourceNameS3MaxObjects]
+			usedMaxBuckets := used[consts.ResourceNameS3MaxBuckets]
+			g.Expect(usedMaxSize.Cmp(maxSize)).To(BeZero())
+			g.Expect(usedMaxObjects.Cmp(maxObjects)).To(BeZero())
+			g.Expect(usedMaxBuckets.Value()).To(Equal(maxBuckets))
+		}
+
+		BeforeEach(func() {
+			for _, namespace := range []string{teamNamespace, otherNamespace} {
+				Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, &v1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   namespace,
+						Labels: map[string]string{consts.LabelTeam: team},
+					},
+				}))).To(Succeed())
+			}
+
+			clusterQuota := &openshiftquota.ClusterResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: team},
+				Spec: openshiftquota.ClusterResourceQuotaSpec{
+					Selector: openshiftquota.ClusterResourceQuotaSelector{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{consts.LabelTeam: team}},
+					},
+					Quota: v1.ResourceQuotaSpec{
+						Hard: v1.ResourceList{
+							consts.ResourceNameS3MaxSize:    resource.MustParse("10k"),
+							consts.ResourceNameS3MaxObjects: resource.MustParse("10M"),
+							consts.ResourceNameS3MaxBuckets: resource.MustParse("100"),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, clusterQuota)).To(Succeed())
+
+			By("Expect a namespace which left the team to be in the status of the ClusterResourceQuota")
+			clusterQuota.Status = openshiftquota.ClusterResourceQuotaStatus{
+				Total: v1.ResourceQuotaStatus{
+					Used: v1.ResourceList{consts.ResourceNameS3MaxSize: resource.MustParse("5k")},
+				},
+				Namespaces: openshiftquota.ResourceQuotasStatusByNamespace{{
+					Namespace: staleNamespace,
+					Status: v1.ResourceQuotaStatus{
+						Used: v1.ResourceList{
+							v1.ResourcePods:              resource.MustParse("1"),
+							consts.ResourceNameS3MaxSize: resource.MustParse("5k"),
+						},
+					},
+				}},
+			}
+			Expect(k8sClient.Status().Update(ctx, clusterQuota)).To(Succeed())
+
+			teamQuotaClaim = getS3UserClaim()
+			teamQuotaClaim.Namespace = teamNamespace
+			Expect(k8sClient.Create(ctx, teamQuotaClaim)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, teamQuotaClaim))).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(apierrors.IsNotFound(
+					k8sClient.Get(ctx, client.ObjectKeyFromObject(teamQuotaClaim), &s3v1alpha1.S3UserClaim{}),
+				)).To(BeTrue())
+			}).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, &openshiftquota.ClusterResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: team},
+			})).To(Succeed())
+		})
+
+		It("Should publish the requested quotas of the team to the status of the ClusterResourceQuota", func() {
+			By("Expect the total and the namespaces to sum up the quota of the S3UserClaim")
+			Eventually(func(g Gomega) {
+				clusterQuota := getClusterQuota(g)
+				expectUsed(g, clusterQuota.Status.Total.Used, quotaMaxSize, quotaMaxObjects, int64(quotaMaxBuckets))
+				expectUsed(g, namespaceStatus(g, clusterQuota, teamNamespace).Used,
+					quotaMaxSize, quotaMaxObjects, int64(quotaMaxBuckets))
+				expectUsed(g, namespaceStatus(g, clusterQuota, otherNamespace).Used,
+					resource.Quantity{}, resource.Quantity{}, 0)
+
+				// The S3 resources of the namespace which left the team are removed, the other resources are kept
+				staleUsed := namespaceStatus(g, clusterQuota, staleNamespace).Used
+				g.Expect(staleUsed).NotTo(HaveKey(consts.ResourceNameS3MaxSize))
+				g.Expect(staleUsed).To(HaveKey(v1.ResourcePods))
+			}).Should(Succeed())
+
+			By("Expect the quota of the S3UserClaim to be released once it's deleted")
+			Expect(k8sClient.Delete(ctx, teamQuotaClaim)).To(Succeed())
+			Eventually(func(g Gomega) {
+				clusterQuota := getClusterQuota(g)
+				expectUsed(g, clusterQuota.Status.Total.Used, resource.Quantity{}, resource.Quantity{}, 0)
+				expectUsed(g, namespaceStatus(g, clusterQuota, teamNamespace).Used,
+					resource.Quantity{}, resource.Quantity{}, 0)
+			}).Should(Succeed())
+		})
 	})
 })
@@ -22,7 +22,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ceph/go-ceph/rgw/admin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	openshiftquota "github.com/openshift/api/quota"
@@ -62,7 +61,10 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "..", "config", "crd", "bases"),
+			filepath.Join("..", "..", "..", "config", "external-crd"),
+		},
 		ErrorIfCRDPathMissing: true,
 	}
 
@@ -89,10 +91,16 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	cfg := config.DefaultConfig
-	co, err := admin.New(cfg.Rgw.Endpoint, cfg.Rgw.AccessKey, cfg.Rgw.SecretKey, nil)
-	Expect(err).NotTo(HaveOccurred(), "failed to create rgw client")
+	// Run several workers to make the race detector catch any state shared between reconciles
+	cfg.Controllers = &config.Controllers{
+		S3UserClaim: &config.Controller{MaxConcurrentReconciles: 4},
+		S3Bucket:    &config.S3BucketController{MaxConcurrentReconciles: 4},
+	}
+	// The adoption tests adopt a user of another tenant in the default namespace
+	cfg.Adoption = &config.Adoption{AllowedNamespaces: []string{"default"}}
+	s3v1alpha1.DefaultS3UserClass = cfg.S3UserClass
 
-	s3UserClaimReconciler := NewReconciler(k8sManager, &cfg, co)
+	s3UserClaimReconciler := NewReconciler(k8sManager, &cfg)
 	err = s3UserClaimReconciler.SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred(), "failed to setup s3UserClaim controller with manager")
 
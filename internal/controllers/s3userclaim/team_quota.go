@@ -0,0 +1,143 @@
+package s3userclaim
+
+import (
+	"context"
+	goerrors "errors"
+	"sort"
+
+	"github.com/opdev/subreconciler"
+	openshiftquota "github.com/openshift/api/quota/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+func (r *reconcileRequest) updateTeamQuotaStatusInclusive(ctx context.Context) (*ctrl.Result, error) {
+	return r.updateTeamQuotaStatus(ctx, true)
+}
+
+func (r *reconcileRequest) updateTeamQuotaStatusExclusive(ctx context.Context) (*ctrl.Result, error) {
+	return r.updateTeamQuotaStatus(ctx, false)
+}
+
+// updateTeamQuotaStatus publishes the S3 consumption of the team of the s3UserClaim, in total and per namespace, to the
+// status of its ClusterResourceQuota, so it's shown like the other resources of the team. Like in the ResourceQuotas of
+// the namespaces, the consumption is the sum of the requested quotas, or the actual usage in the overcommit mode.
+func (r *reconcileRequest) updateTeamQuotaStatus(ctx context.Context, addCurrentQuota bool) (*ctrl.Result, error) {
+	// Only the ClusterResourceQuotas have a status which the consumption is published to
+	if s3v1alpha1.TeamQuotaBackend != consts.TeamQuotaBackendOpenShift {
+		return subreconciler.ContinueReconciling()
+	}
+	teamQuotas, err := s3v1alpha1.GetTeamQuotas(ctx, r.Client, r.s3UserClaimNamespace)
+	switch {
+	case goerrors.Is(err, consts.ErrClusterQuotaNotDefined):
+		return subreconciler.ContinueReconciling()
+	case err != nil:
+		r.logger.Error(err, "failed to get team quotas")
+		return subreconciler.Requeue()
+	}
+
+	s3UserClaim := r.s3UserClaim.DeepCopy()
+	s3UserClaim.Status.Usage = r.usage
+	classQuotas := s3v1alpha1.NewClassQuotas(r.uncachedReader)
+	for i := range teamQuotas {
+		teamQuota := &teamQuotas[i]
+		var usedQuotas map[string]*s3v1alpha1.UserQuota
+		if s3v1alpha1.QuotaEnforcement.IsOvercommit() {
+			usedQuotas, err = s3v1alpha1.CalculateClusterUsageByNamespace(ctx, r.uncachedReader, teamQuota, s3UserClaim,
+				addCurrentQuota)
+		} else {
+			usedQuotas, err = s3v1alpha1.CalculateClusterUsedQuotaByNamespace(ctx, r.uncachedReader, classQuotas,
+				teamQuota, s3UserClaim, addCurrentQuota)
+		}
+		if err != nil {
+			r.logger.Error(err, "failed to calculate cluster resource used quota", "team", teamQuota.Name)
+			return subreconciler.Requeue()
+		}
+		if err := r.updateClusterQuotaStatus(ctx, teamQuota.Name, usedQuotas); err != nil {
+			r.logger.Error(err, "failed to update cluster quota status", "team", teamQuota.Name)
+			return subreconciler.Requeue()
+		}
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+var s3ResourceNames = []corev1.ResourceName{
+	consts.ResourceNameS3MaxSize, consts.ResourceNameS3MaxObjects, consts.ResourceNameS3MaxBuckets,
+}
+
+// updateClusterQuotaStatus sets the S3 resources of the total and the namespaces status of the ClusterResourceQuota,
+// the other resources are left to their own controller. The total is the sum of the namespaces of the team.
+func (r *reconcileRequest) updateClusterQuotaStatus(ctx context.Context, name string,
+	usedQuotas map[string]*s3v1alpha1.UserQuota) error {
+	namespaces := make([]string, 0, len(usedQuotas))
+	totalUsedQuota := &s3v1alpha1.UserQuota{}
+	for namespace, usedQuota := range usedQuotas {
+		namespaces = append(namespaces, namespace)
+		totalUsedQuota.MaxSize.Add(usedQuota.MaxSize)
+		totalUsedQuota.MaxObjects.Add(usedQuota.MaxObjects)
+		totalUsedQuota.MaxBuckets += usedQuota.MaxBuckets
+	}
+	sort.Strings(namespaces)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		clusterQuota := &openshiftquota.ClusterResourceQuota{}
+		if err := r.uncachedReader.Get(ctx, types.NamespacedName{Name: name}, clusterQuota); err != nil {
+			return err
+		}
+
+		status := clusterQuota.Status.DeepCopy()
+		if status.Total.Hard == nil {
+			status.Total.Hard = corev1.ResourceList{}
+		}
+		for _, resourceName := range s3ResourceNames {
+			if hard, ok := clusterQuota.Spec.Quota.Hard[resourceName]; ok {
+				status.Total.Hard[resourceName] = hard
+			}
+		}
+		assignUsedQuotaToResourceStatus(&status.Total, totalUsedQuota)
+		status.Namespaces = removeStaleNamespaceResources(status.Namespaces, usedQuotas)
+		for _, namespace := range namespaces {
+			i := 0
+			for i < len(status.Namespaces) && status.Namespaces[i].Namespace != namespace {
+				i++
+			}
+			if i == len(status.Namespaces) {
+				status.Namespaces = append(status.Namespaces,
+					openshiftquota.ResourceQuotaStatusByNamespace{Namespace: namespace})
+			}
+			assignUsedQuotaToResourceStatus(&status.Namespaces[i].Status, usedQuotas[namespace])
+		}
+
+		if apiequality.Semantic.DeepEqual(clusterQuota.Status, *status) {
+			return nil
+		}
+		clusterQuota.Status = *status
+		return r.Status().Update(ctx, clusterQuota)
+	})
+}
+
+// removeStaleNamespaceResources removes the S3 resources from the status of the namespaces which are no longer in the
+// team, and the namespaces which are left without any resource
+func removeStaleNamespaceResources(statuses openshiftquota.ResourceQuotasStatusByNamespace,
+	usedQuotas map[string]*s3v1alpha1.UserQuota) openshiftquota.ResourceQuotasStatusByNamespace {
+	kept := statuses[:0]
+	for _, namespaceStatus := range statuses {
+		if _, ok := usedQuotas[namespaceStatus.Namespace]; !ok {
+			for _, resourceName := range s3ResourceNames {
+				delete(namespaceStatus.Status.Hard, resourceName)
+				delete(namespaceStatus.Status.Used, resourceName)
+			}
+			if len(namespaceStatus.Status.Hard) == 0 && len(namespaceStatus.Status.Used) == 0 {
+				continue
+			}
+		}
+		kept = append(kept, namespaceStatus)
+	}
+	return kept
+}
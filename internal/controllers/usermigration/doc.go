@@ -0,0 +1,23 @@
+package usermigration
+
+// This package contains the controller for migrating a Ceph user and its buckets to another namespace.
+//
+// The Ceph tenant of a user is derived from the namespace of its S3UserClaim, so a user can't simply change its
+// namespace. Instead, a UserMigration creates a new user in the target namespace and links the buckets to it.
+//
+// A migration runs once like a job and its spec can't be changed. Each phase is recorded in the status before it
+// starts and its progress is recorded as it goes, so a failed or interrupted reconcile retries from where it stopped.
+
+// Overall migration flow:
+//
+// 1. Pending: record the source and target Ceph users
+// 2. CreatingTarget: create the target S3UserClaim as a copy of the source one and wait until it's ready
+// 3. LinkingBuckets: pause the S3Buckets of the migrated buckets and link the buckets to the target user one by one
+// 4. MovingBuckets: create the S3Buckets in the target namespace, importing the linked buckets, and then remove the
+// paused S3Buckets while retaining their buckets
+// 5. DeletingSource: delete the source S3UserClaim unless its user still owns buckets or S3Buckets
+// 6. Succeeded
+//
+// If linking a bucket fails or a target S3Bucket is rejected, the migration goes to the RollingBack phase: the target
+// S3Buckets are removed while retaining their buckets, the linked buckets are linked back to the source user, the
+// S3Buckets are resumed, the target S3UserClaim is deleted and the migration is marked as Failed.
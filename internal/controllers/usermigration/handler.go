@@ -0,0 +1,146 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usermigration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/go-logr/logr"
+	"github.com/opdev/subreconciler"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/config"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/s3userclass"
+)
+
+// Reconciler reconciles a UserMigration object
+type Reconciler struct {
+	client.Client
+	scheme        *runtime.Scheme
+	classResolver *s3userclass.Resolver
+
+	// configurations
+	maxConcurrentReconciles int
+}
+
+// reconcileRequest holds the state of a single reconciliation. A new one is created on each call to Reconcile
+// so that concurrent reconciles never share any mutable state.
+type reconcileRequest struct {
+	*Reconciler
+	logger    logr.Logger
+	rgwClient *admin.API
+
+	userMigration    *s3v1alpha1.UserMigration
+	sourceClaim      *s3v1alpha1.S3UserClaim
+	targetClaimName  string
+	sourceTenant     string
+	sourceUserFullId string
+	targetTenant     string
+	targetUserFullId string
+}
+
+func NewReconciler(mgr manager.Manager, cfg *config.Config) *Reconciler {
+	return &Reconciler{
+		Client:        mgr.GetClient(),
+		scheme:        mgr.GetScheme(),
+		classResolver: s3userclass.NewResolver(mgr.GetClient(), cfg),
+
+		maxConcurrentReconciles: cfg.Controllers.UserMigration.MaxConcurrentReconciles,
+	}
+}
+
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=usermigrations,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=usermigrations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3userclaims,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3buckets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=s3.snappcloud.io,resources=s3userclasses,verbs=get;list;watch
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	rr := &reconcileRequest{
+		Reconciler:    r,
+		logger:        log.FromContext(ctx),
+		userMigration: &s3v1alpha1.UserMigration{},
+		sourceClaim:   &s3v1alpha1.S3UserClaim{},
+	}
+	return rr.reconcile(ctx, req)
+}
+
+func (r *reconcileRequest) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	switch err := r.Get(ctx, req.NamespacedName, r.userMigration); {
+	case apierrors.IsNotFound(err):
+		return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+	case err != nil:
+		r.logger.Error(err, "failed to fetch object")
+		return subreconciler.Evaluate(subreconciler.Requeue())
+	}
+	if r.userMigration.IsFinished() {
+		return subreconciler.Evaluate(subreconciler.DoNotRequeue())
+	}
+
+	sourceClaimRef := r.userMigration.Spec.SourceClaim
+	switch err := r.Get(ctx, types.NamespacedName{Namespace: sourceClaimRef.Namespace, Name: sourceClaimRef.Name},
+		r.sourceClaim); {
+	case apierrors.IsNotFound(err) && r.userMigration.Status.Phase == s3v1alpha1.UserMigrationPhaseDeletingSource:
+		// The source claim is already deleted by the migration
+		return r.complete(ctx)
+	case apierrors.IsNotFound(err):
+		return r.fail(ctx, fmt.Sprintf("source s3UserClaim %s/%s not found", sourceClaimRef.Namespace,
+			sourceClaimRef.Name))
+	case err != nil:
+		r.logger.Error(err, "failed to get source s3UserClaim")
+		return subreconciler.Evaluate(subreconciler.Requeue())
+	}
+
+	if err := r.initVars(ctx); err != nil {
+		r.logger.Error(err, "failed to resolve s3UserClass")
+		return subreconciler.Evaluate(subreconciler.Requeue())
+	}
+
+	if r.userMigration.Status.Phase == s3v1alpha1.UserMigrationPhaseRollingBack {
+		return r.Rollback(ctx)
+	}
+	return r.Migrate(ctx)
+}
+
+func (r *reconcileRequest) initVars(ctx context.Context) error {
+	s3UserClass, err := r.classResolver.Resolve(ctx, r.sourceClaim.Spec.S3UserClass)
+	if err != nil {
+		return err
+	}
+	if r.rgwClient, err = s3UserClass.RgwClient(); err != nil {
+		return fmt.Errorf("failed to create rgw client, %w", err)
+	}
+
+	var sourceUserId, targetUserId string
+	r.sourceTenant, sourceUserId = s3UserClass.CephUser(r.sourceClaim.Namespace, r.sourceClaim.Name,
+		r.sourceClaim.Spec.ExistingUser)
+	r.sourceUserFullId = s3userclass.FullUserID(r.sourceTenant, sourceUserId)
+
+	r.targetClaimName = r.userMigration.GetTargetClaimName()
+	r.targetTenant, targetUserId = s3UserClass.CephUser(r.userMigration.Spec.TargetNamespace, r.targetClaimName, "")
+	r.targetUserFullId = s3userclass.FullUserID(r.targetTenant, targetUserId)
+	return nil
+}
@@ -0,0 +1,510 @@
+package usermigration
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/opdev/subreconciler"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// phaseOrder is the order which the phases of a successful migration go through
+var phaseOrder = map[s3v1alpha1.UserMigrationPhase]int{
+	"":                                   0,
+	s3v1alpha1.UserMigrationPhasePending: 1,
+	s3v1alpha1.UserMigrationPhaseCreatingTarget: 2,
+	s3v1alpha1.UserMigrationPhaseLinkingBuckets: 3,
+	s3v1alpha1.UserMigrationPhaseMovingBuckets:  4,
+	s3v1alpha1.UserMigrationPhaseDeletingSource: 5,
+	s3v1alpha1.UserMigrationPhaseSucceeded:      6,
+}
+
+// Migrate moves the user and its buckets to the target namespace
+func (r *reconcileRequest) Migrate(ctx context.Context) (ctrl.Result, error) {
+	subrecs := []subreconciler.Fn{
+		r.start,
+		r.createTargetClaim,
+		r.linkBuckets,
+		r.moveS3Buckets,
+		r.deleteSourceClaim,
+	}
+	for _, subrec := range subrecs {
+		result, err := subrec(ctx)
+		if subreconciler.ShouldHaltOrRequeue(result, err) {
+			return subreconciler.Evaluate(result, err)
+		}
+	}
+
+	return r.complete(ctx)
+}
+
+// Rollback removes the target S3Buckets, links the linked buckets back to the source user and undoes the rest of the
+// migration
+func (r *reconcileRequest) Rollback(ctx context.Context) (ctrl.Result, error) {
+	subrecs := []subreconciler.Fn{
+		r.deleteTargetS3Buckets,
+		r.unlinkBuckets,
+		r.resumeS3Buckets,
+		r.deleteTargetClaim,
+	}
+	for _, subrec := range subrecs {
+		result, err := subrec(ctx)
+		if subreconciler.ShouldHaltOrRequeue(result, err) {
+			return subreconciler.Evaluate(result, err)
+		}
+	}
+
+	now := metav1.Now()
+	r.userMigration.Status.Phase = s3v1alpha1.UserMigrationPhaseFailed
+	r.userMigration.Status.CompletionTime = &now
+	return subreconciler.Evaluate(r.updateStatus(ctx))
+}
+
+func (r *reconcileRequest) start(ctx context.Context) (*ctrl.Result, error) {
+	if !r.enterPhase(s3v1alpha1.UserMigrationPhasePending) {
+		return subreconciler.ContinueReconciling()
+	}
+	now := metav1.Now()
+	r.userMigration.Status.StartTime = &now
+	r.userMigration.Status.SourceUser = r.sourceUserFullId
+	r.userMigration.Status.TargetUser = r.targetUserFullId
+	return r.updateStatus(ctx)
+}
+
+// createTargetClaim creates the target S3UserClaim as a copy of the source one and waits until it's ready
+func (r *reconcileRequest) createTargetClaim(ctx context.Context) (*ctrl.Result, error) {
+	if !r.enterPhase(s3v1alpha1.UserMigrationPhaseCreatingTarget) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	targetClaim := &s3v1alpha1.S3UserClaim{}
+	switch err := r.Get(ctx, r.targetClaimKey(), targetClaim); {
+	case apierrors.IsNotFound(err):
+		targetClaim = r.assembleTargetClaim()
+		switch err := r.Create(ctx, targetClaim); {
+		case apierrors.IsInvalid(err) || apierrors.IsForbidden(err) || apierrors.IsBadRequest(err):
+			// The target claim is rejected, e.g. the quota of the target namespace isn't enough
+			return r.failStep(ctx, fmt.Sprintf("failed to create target s3UserClaim, %s", err))
+		case err != nil:
+			r.logger.Error(err, "failed to create target s3UserClaim")
+			return subreconciler.Requeue()
+		}
+		r.logger.Info("created target s3UserClaim", "namespace", targetClaim.Namespace, "name", targetClaim.Name)
+		return r.waitFor(ctx, "waiting for the target s3UserClaim to become ready")
+	case err != nil:
+		r.logger.Error(err, "failed to get target s3UserClaim")
+		return subreconciler.Requeue()
+	}
+
+	if targetClaim.Labels[consts.LabelUserMigration] != r.userMigration.Name {
+		return r.failStep(ctx, consts.ErrTargetClaimExists.Error())
+	}
+	if !meta.IsStatusConditionTrue(targetClaim.Status.Conditions, consts.ConditionTypeReady) {
+		return r.waitFor(ctx, "waiting for the target s3UserClaim to become ready")
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// linkBuckets pauses the S3Buckets of the migrated buckets and links the buckets to the target user one by one. The
+// migration is rolled back if a bucket can't be linked.
+func (r *reconcileRequest) linkBuckets(ctx context.Context) (*ctrl.Result, error) {
+	if !r.enterPhase(s3v1alpha1.UserMigrationPhaseLinkingBuckets) {
+		return subreconciler.ContinueReconciling()
+	}
+	status := &r.userMigration.Status
+
+	if status.Buckets == nil {
+		buckets := r.userMigration.Spec.Buckets
+		if len(buckets) == 0 {
+			var err error
+			if buckets, err = r.rgwClient.ListUsersBuckets(ctx, r.sourceUserFullId); err != nil {
+				r.logger.Error(err, "failed to list the buckets of the source user")
+				return subreconciler.Requeue()
+			}
+		}
+		status.Buckets = buckets
+		if result, err := r.updateStatus(ctx); subreconciler.ShouldHaltOrRequeue(result, err) {
+			return result, err
+		}
+	}
+
+	s3Buckets, err := r.listSourceS3Buckets(ctx)
+	if err != nil {
+		r.logger.Error(err, "failed to list source s3Buckets")
+		return subreconciler.Requeue()
+	}
+	for i := range s3Buckets {
+		s3Bucket := &s3Buckets[i]
+		if s3Bucket.Annotations[consts.AnnotationPaused] != "" {
+			continue
+		}
+		if s3Bucket.Annotations == nil {
+			s3Bucket.Annotations = map[string]string{}
+		}
+		s3Bucket.Annotations[consts.AnnotationPaused] = r.userMigration.Name
+		if err := r.Update(ctx, s3Bucket); err != nil {
+			r.logger.Error(err, "failed to pause s3Bucket", "s3Bucket", s3Bucket.Name)
+			return subreconciler.Requeue()
+		}
+	}
+
+	for _, bucket := range status.Buckets {
+		if contains(status.LinkedBuckets, bucket) {
+			continue
+		}
+		err := r.linkBucket(ctx, bucket, r.sourceTenant, r.sourceUserFullId, r.targetTenant, r.targetUserFullId)
+		if err != nil {
+			r.logger.Error(err, "failed to link bucket to the target user, rolling back", "bucket", bucket)
+			return r.rollBack(ctx, fmt.Sprintf("failed to link bucket %s, %s", bucket, err))
+		}
+		status.LinkedBuckets = append(status.LinkedBuckets, bucket)
+		if result, err := r.updateStatus(ctx); subreconciler.ShouldHaltOrRequeue(result, err) {
+			return result, err
+		}
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// moveS3Buckets creates the S3Buckets of the linked buckets in the target namespace and then removes the paused ones
+// while retaining their buckets. The migration is rolled back if a target S3Bucket is rejected, which can only happen
+// before any source S3Bucket is removed.
+func (r *reconcileRequest) moveS3Buckets(ctx context.Context) (*ctrl.Result, error) {
+	if !r.enterPhase(s3v1alpha1.UserMigrationPhaseMovingBuckets) {
+		return subreconciler.ContinueReconciling()
+	}
+	status := &r.userMigration.Status
+
+	s3Buckets, err := r.listSourceS3Buckets(ctx)
+	if err != nil {
+		r.logger.Error(err, "failed to list source s3Buckets")
+		return subreconciler.Requeue()
+	}
+	for i := range s3Buckets {
+		s3Bucket := &s3Buckets[i]
+		if contains(status.MovedS3Buckets, s3Bucket.Name) {
+			continue
+		}
+
+		targetS3Bucket := r.assembleTargetS3Bucket(s3Bucket)
+		switch err := r.Create(ctx, targetS3Bucket); {
+		case apierrors.IsInvalid(err) || apierrors.IsForbidden(err) || apierrors.IsBadRequest(err):
+			r.logger.Error(err, "target s3Bucket is rejected, rolling back", "s3Bucket", s3Bucket.Name)
+			return r.rollBack(ctx, fmt.Sprintf("failed to create target s3Bucket %s, %s", s3Bucket.Name, err))
+		case err != nil && !apierrors.IsAlreadyExists(err):
+			r.logger.Error(err, "failed to create target s3Bucket", "s3Bucket", s3Bucket.Name)
+			return r.waitFor(ctx, fmt.Sprintf("failed to create target s3Bucket %s, %s", s3Bucket.Name, err))
+		}
+	}
+
+	for i := range s3Buckets {
+		s3Bucket := &s3Buckets[i]
+		if contains(status.MovedS3Buckets, s3Bucket.Name) {
+			continue
+		}
+
+		if s3Bucket.Spec.S3DeletionPolicy != consts.DeletionPolicyRetain {
+			s3Bucket.Spec.S3DeletionPolicy = consts.DeletionPolicyRetain
+			if err := r.Update(ctx, s3Bucket); err != nil {
+				r.logger.Error(err, "failed to retain the bucket of source s3Bucket", "s3Bucket", s3Bucket.Name)
+				return subreconciler.Requeue()
+			}
+		}
+		if err := r.Delete(ctx, s3Bucket); client.IgnoreNotFound(err) != nil {
+			r.logger.Error(err, "failed to delete source s3Bucket", "s3Bucket", s3Bucket.Name)
+			return subreconciler.Requeue()
+		}
+
+		status.MovedS3Buckets = append(status.MovedS3Buckets, s3Bucket.Name)
+		if result, err := r.updateStatus(ctx); subreconciler.ShouldHaltOrRequeue(result, err) {
+			return result, err
+		}
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// deleteSourceClaim deletes the source S3UserClaim, which removes the source user regarding its deletion policy. The
+// claim is kept if its user still owns buckets or is referenced by S3Buckets which weren't migrated. The moved
+// S3Buckets are waited for until they're removed.
+func (r *reconcileRequest) deleteSourceClaim(ctx context.Context) (*ctrl.Result, error) {
+	if !r.enterPhase(s3v1alpha1.UserMigrationPhaseDeletingSource) {
+		return subreconciler.ContinueReconciling()
+	}
+
+	buckets, err := r.rgwClient.ListUsersBuckets(ctx, r.sourceUserFullId)
+	if err != nil {
+		r.logger.Error(err, "failed to list the buckets of the source user")
+		return subreconciler.Requeue()
+	}
+	s3BucketList := &s3v1alpha1.S3BucketList{}
+	if err := r.List(ctx, s3BucketList, client.InNamespace(r.sourceClaim.Namespace)); err != nil {
+		r.logger.Error(err, "failed to list source s3Buckets")
+		return subreconciler.Requeue()
+	}
+	referenced, removing := false, false
+	for _, s3Bucket := range s3BucketList.Items {
+		switch {
+		case s3Bucket.Spec.S3UserRef != r.sourceClaim.Name:
+		case s3Bucket.DeletionTimestamp != nil || contains(r.userMigration.Status.MovedS3Buckets, s3Bucket.Name):
+			removing = true
+		default:
+			referenced = true
+		}
+	}
+	if len(buckets) > 0 || referenced {
+		r.userMigration.Status.Message = "the source s3UserClaim is kept since it still owns buckets"
+		return subreconciler.ContinueReconciling()
+	}
+	if removing {
+		return r.waitFor(ctx, "waiting for the moved source s3Buckets to be removed")
+	}
+
+	if err := r.Delete(ctx, r.sourceClaim); client.IgnoreNotFound(err) != nil {
+		r.logger.Error(err, "failed to delete source s3UserClaim")
+		return subreconciler.Requeue()
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *reconcileRequest) complete(ctx context.Context) (ctrl.Result, error) {
+	now := metav1.Now()
+	r.userMigration.Status.Phase = s3v1alpha1.UserMigrationPhaseSucceeded
+	r.userMigration.Status.CompletionTime = &now
+	return subreconciler.Evaluate(r.updateStatus(ctx))
+}
+
+// deleteTargetS3Buckets removes the S3Buckets created by the migration in the target namespace while retaining their
+// buckets, which are linked back to the source user
+func (r *reconcileRequest) deleteTargetS3Buckets(ctx context.Context) (*ctrl.Result, error) {
+	s3BucketList := &s3v1alpha1.S3BucketList{}
+	if err := r.List(ctx, s3BucketList, client.InNamespace(r.userMigration.Spec.TargetNamespace),
+		client.MatchingLabels{consts.LabelUserMigration: r.userMigration.Name}); err != nil {
+		r.logger.Error(err, "failed to list target s3Buckets")
+		return subreconciler.Requeue()
+	}
+	for i := range s3BucketList.Items {
+		s3Bucket := &s3BucketList.Items[i]
+		if s3Bucket.Spec.S3DeletionPolicy != consts.DeletionPolicyRetain {
+			s3Bucket.Spec.S3DeletionPolicy = consts.DeletionPolicyRetain
+			if err := r.Update(ctx, s3Bucket); err != nil {
+				r.logger.Error(err, "failed to retain the bucket of target s3Bucket", "s3Bucket", s3Bucket.Name)
+				return subreconciler.Requeue()
+			}
+		}
+		if err := r.Delete(ctx, s3Bucket); client.IgnoreNotFound(err) != nil {
+			r.logger.Error(err, "failed to delete target s3Bucket", "s3Bucket", s3Bucket.Name)
+			return subreconciler.Requeue()
+		}
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// unlinkBuckets links the linked buckets back to the source user
+func (r *reconcileRequest) unlinkBuckets(ctx context.Context) (*ctrl.Result, error) {
+	status := &r.userMigration.Status
+	for len(status.LinkedBuckets) > 0 {
+		bucket := status.LinkedBuckets[len(status.LinkedBuckets)-1]
+		err := r.linkBucket(ctx, bucket, r.targetTenant, r.targetUserFullId, r.sourceTenant, r.sourceUserFullId)
+		if err != nil {
+			r.logger.Error(err, "failed to link bucket back to the source user", "bucket", bucket)
+			return subreconciler.Requeue()
+		}
+		status.LinkedBuckets = status.LinkedBuckets[:len(status.LinkedBuckets)-1]
+		if result, err := r.updateStatus(ctx); subreconciler.ShouldHaltOrRequeue(result, err) {
+			return result, err
+		}
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// resumeS3Buckets removes the pause of the S3Buckets paused by the migration
+func (r *reconcileRequest) resumeS3Buckets(ctx context.Context) (*ctrl.Result, error) {
+	s3Buckets, err := r.listSourceS3Buckets(ctx)
+	if err != nil {
+		r.logger.Error(err, "failed to list source s3Buckets")
+		return subreconciler.Requeue()
+	}
+	for i := range s3Buckets {
+		s3Bucket := &s3Buckets[i]
+		if s3Bucket.Annotations[consts.AnnotationPaused] != r.userMigration.Name {
+			continue
+		}
+		delete(s3Bucket.Annotations, consts.AnnotationPaused)
+		if err := r.Update(ctx, s3Bucket); err != nil {
+			r.logger.Error(err, "failed to resume s3Bucket", "s3Bucket", s3Bucket.Name)
+			return subreconciler.Requeue()
+		}
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// deleteTargetClaim deletes the target S3UserClaim if it's created by the migration
+func (r *reconcileRequest) deleteTargetClaim(ctx context.Context) (*ctrl.Result, error) {
+	targetClaim := &s3v1alpha1.S3UserClaim{}
+	switch err := r.Get(ctx, r.targetClaimKey(), targetClaim); {
+	case apierrors.IsNotFound(err):
+		return subreconciler.ContinueReconciling()
+	case err != nil:
+		r.logger.Error(err, "failed to get target s3UserClaim")
+		return subreconciler.Requeue()
+	}
+	if targetClaim.Labels[consts.LabelUserMigration] != r.userMigration.Name {
+		return subreconciler.ContinueReconciling()
+	}
+	if err := r.Delete(ctx, targetClaim); client.IgnoreNotFound(err) != nil {
+		r.logger.Error(err, "failed to delete target s3UserClaim")
+		return subreconciler.Requeue()
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// linkBucket links the bucket of the "from" user to the "to" user. It's a no-op if the bucket is already linked.
+func (r *reconcileRequest) linkBucket(ctx context.Context, bucket, fromTenant, fromUser, toTenant, toUser string) error {
+	if linked, err := r.rgwClient.GetBucketInfo(ctx, admin.Bucket{Bucket: fullBucketName(toTenant, bucket)}); err == nil &&
+		linked.Owner == toUser {
+		return nil
+	}
+
+	fromBucket := fullBucketName(fromTenant, bucket)
+	info, err := r.rgwClient.GetBucketInfo(ctx, admin.Bucket{Bucket: fromBucket})
+	if err != nil {
+		return fmt.Errorf("failed to get the bucket info, %w", err)
+	}
+	if info.Owner != fromUser {
+		return goerrors.New("the bucket isn't owned by " + fromUser)
+	}
+	return r.rgwClient.LinkBucket(ctx, admin.BucketLinkInput{Bucket: fromBucket, BucketID: info.ID, UID: toUser})
+}
+
+// listSourceS3Buckets lists the S3Buckets of the source claim whose buckets are migrated
+func (r *reconcileRequest) listSourceS3Buckets(ctx context.Context) ([]s3v1alpha1.S3Bucket, error) {
+	s3BucketList := &s3v1alpha1.S3BucketList{}
+	if err := r.List(ctx, s3BucketList, client.InNamespace(r.sourceClaim.Namespace)); err != nil {
+		return nil, err
+	}
+	var s3Buckets []s3v1alpha1.S3Bucket
+	for _, s3Bucket := range s3BucketList.Items {
+		if s3Bucket.Spec.S3UserRef == r.sourceClaim.Name && s3Bucket.DeletionTimestamp == nil &&
+			contains(r.userMigration.Status.Buckets, s3Bucket.GetBucketName()) {
+			s3Buckets = append(s3Buckets, s3Bucket)
+		}
+	}
+	return s3Buckets, nil
+}
+
+func (r *reconcileRequest) assembleTargetClaim() *s3v1alpha1.S3UserClaim {
+	spec := r.sourceClaim.Spec.DeepCopy()
+	// The target user is a new user in the tenant of the target namespace
+	spec.ExistingUser = ""
+	return &s3v1alpha1.S3UserClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.userMigration.Spec.TargetNamespace,
+			Name:      r.targetClaimName,
+			Labels:    map[string]string{consts.LabelUserMigration: r.userMigration.Name},
+		},
+		Spec: *spec,
+	}
+}
+
+func (r *reconcileRequest) assembleTargetS3Bucket(s3Bucket *s3v1alpha1.S3Bucket) *s3v1alpha1.S3Bucket {
+	spec := s3Bucket.Spec.DeepCopy()
+	spec.S3UserRef = r.targetClaimName
+	// The bucket is already linked, importing it records the migration in the status of the S3Bucket
+	spec.ExistingBucket = fullBucketName(r.targetTenant, s3Bucket.GetBucketName())
+	spec.BucketName = ""
+	spec.GenerateBucketName = nil
+	return &s3v1alpha1.S3Bucket{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.userMigration.Spec.TargetNamespace,
+			Name:      s3Bucket.Name,
+			Labels:    map[string]string{consts.LabelUserMigration: r.userMigration.Name},
+		},
+		Spec: *spec,
+	}
+}
+
+// enterPhase reports whether the step of the phase still has to run and moves the migration into the phase
+func (r *reconcileRequest) enterPhase(phase s3v1alpha1.UserMigrationPhase) bool {
+	if phaseOrder[r.userMigration.Status.Phase] > phaseOrder[phase] {
+		return false
+	}
+	if r.userMigration.Status.Phase != phase {
+		r.logger.Info("entering phase", "phase", phase)
+		r.userMigration.Status.Phase = phase
+		r.userMigration.Status.Message = ""
+	}
+	return true
+}
+
+// waitFor records the message and checks the step again after a while
+func (r *reconcileRequest) waitFor(ctx context.Context, message string) (*ctrl.Result, error) {
+	r.userMigration.Status.Message = message
+	if result, err := r.updateStatus(ctx); subreconciler.ShouldHaltOrRequeue(result, err) {
+		return result, err
+	}
+	return subreconciler.RequeueWithDelay(consts.UserMigrationPollInterval)
+}
+
+// rollBack moves the migration to the RollingBack phase, which undoes the steps done so far
+func (r *reconcileRequest) rollBack(ctx context.Context, message string) (*ctrl.Result, error) {
+	r.userMigration.Status.Phase = s3v1alpha1.UserMigrationPhaseRollingBack
+	r.userMigration.Status.Message = message
+	if result, err := r.updateStatus(ctx); subreconciler.ShouldHaltOrRequeue(result, err) {
+		return result, err
+	}
+	return subreconciler.Requeue()
+}
+
+// failStep marks the migration as failed. It's only used before any bucket is linked, so there's nothing to roll back.
+func (r *reconcileRequest) failStep(ctx context.Context, message string) (*ctrl.Result, error) {
+	now := metav1.Now()
+	r.userMigration.Status.Phase = s3v1alpha1.UserMigrationPhaseFailed
+	r.userMigration.Status.Message = message
+	r.userMigration.Status.CompletionTime = &now
+	if result, err := r.updateStatus(ctx); subreconciler.ShouldHaltOrRequeue(result, err) {
+		return result, err
+	}
+	return subreconciler.DoNotRequeue()
+}
+
+func (r *reconcileRequest) fail(ctx context.Context, message string) (ctrl.Result, error) {
+	return subreconciler.Evaluate(r.failStep(ctx, message))
+}
+
+func (r *reconcileRequest) updateStatus(ctx context.Context) (*ctrl.Result, error) {
+	if err := r.Status().Update(ctx, r.userMigration); err != nil {
+		r.logger.Error(err, "failed to update userMigration status")
+		return subreconciler.Requeue()
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+func (r *reconcileRequest) targetClaimKey() types.NamespacedName {
+	return types.NamespacedName{Namespace: r.userMigration.Spec.TargetNamespace, Name: r.targetClaimName}
+}
+
+// fullBucketName returns the name of the bucket which the RGW admin API expects for a bucket of the tenant
+func fullBucketName(tenant, bucket string) string {
+	if tenant == "" {
+		return bucket
+	}
+	return tenant + "/" + bucket
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+const (
+	scopeNamespace = "namespace"
+	scopeTeam      = "team"
+)
+
+var (
+	s3UserClaimsDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "s3userclaims"),
+		"Number of the S3UserClaims by namespace and status of the Ready condition",
+		[]string{"namespace", "ready"}, nil)
+	s3BucketsDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "s3buckets"),
+		"Number of the S3Buckets by namespace and status of the Ready condition",
+		[]string{"namespace", "ready"}, nil)
+	quotaRequestedDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "quota_requested"),
+		"Sum of the quotas of the S3UserClaims of a namespace or a team",
+		[]string{"scope", "name", "resource"}, nil)
+	quotaAllowedDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "quota_allowed"),
+		"Hard limit of the ResourceQuotas of a namespace or the quota of a team",
+		[]string{"scope", "name", "resource"}, nil)
+	userUsageDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "user_usage"),
+		"Actual usage of the Ceph user of an S3UserClaim as last synced from RGW",
+		[]string{"namespace", "s3userclaim", "resource"}, nil)
+)
+
+// collector collects the state of the S3UserClaims and the S3Buckets, the requested and allowed quotas and the usage of
+// the users on each scrape. The objects are read from the cache of the manager, so a scrape doesn't load the API server.
+type collector struct {
+	reader client.Reader
+	logger logr.Logger
+}
+
+// RegisterCollector registers the collector of the operator objects with the controller-runtime registry
+func RegisterCollector(reader client.Reader) {
+	metrics.Registry.MustRegister(&collector{reader: reader, logger: logf.Log.WithName("metrics")})
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s3UserClaimsDesc
+	ch <- s3BucketsDesc
+	ch <- quotaRequestedDesc
+	ch <- quotaAllowedDesc
+	ch <- userUsageDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.MetricsCollectTimeout)
+	defer cancel()
+
+	c.collectS3UserClaims(ctx, ch)
+	c.collectS3Buckets(ctx, ch)
+	c.collectNamespaceQuotas(ctx, ch)
+	c.collectTeamQuotas(ctx, ch)
+}
+
+func (c *collector) collectS3UserClaims(ctx context.Context, ch chan<- prometheus.Metric) {
+	s3UserClaimList := &s3v1alpha1.S3UserClaimList{}
+	if err := c.reader.List(ctx, s3UserClaimList); err != nil {
+		c.logger.Error(err, "failed to list s3UserClaims")
+		return
+	}
+	teamQuotas, err := s3v1alpha1.ListTeamQuotas(ctx, c.reader)
+	if err != nil {
+		c.logger.Error(err, "failed to list team quotas")
+		return
+	}
+	namespaceTeams := map[string][]string{}
+	for _, teamQuota := range teamQuotas {
+		for _, namespace := range teamQuota.Namespaces {
+			namespaceTeams[namespace] = append(namespaceTeams[namespace], teamQuota.Name)
+		}
+	}
+
+	classQuotas := s3v1alpha1.NewClassQuotas(c.reader)
+	counts := map[[2]string]int{}
+	requested := map[[2]string]*s3v1alpha1.UserQuota{}
+	for i := range s3UserClaimList.Items {
+		s3UserClaim := &s3UserClaimList.Items[i]
+		counts[[2]string{s3UserClaim.Namespace, readyStatus(s3UserClaim.Status.Conditions)}]++
+
+		quota, err := classQuotas.S3UserClaimQuota(ctx, s3UserClaim)
+		if err != nil {
+			c.logger.Error(err, "failed to get s3UserClaim quota", "namespace", s3UserClaim.Namespace,
+				"name", s3UserClaim.Name)
+			continue
+		}
+		addQuota(requested, [2]string{scopeNamespace, s3UserClaim.Namespace}, quota)
+		for _, team := range namespaceTeams[s3UserClaim.Namespace] {
+			addQuota(requested, [2]string{scopeTeam, team}, quota)
+		}
+
+		if usage := s3UserClaim.Status.Usage; usage != nil {
+			labels := []string{s3UserClaim.Namespace, s3UserClaim.Name}
+			ch <- prometheus.MustNewConstMetric(userUsageDesc, prometheus.GaugeValue, usage.Size.AsApproximateFloat64(),
+				append(labels, string(consts.ResourceNameS3MaxSize))...)
+			ch <- prometheus.MustNewConstMetric(userUsageDesc, prometheus.GaugeValue,
+				usage.Objects.AsApproximateFloat64(), append(labels, string(consts.ResourceNameS3MaxObjects))...)
+			ch <- prometheus.MustNewConstMetric(userUsageDesc, prometheus.GaugeValue, float64(usage.Buckets),
+				append(labels, string(consts.ResourceNameS3MaxBuckets))...)
+		}
+	}
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(s3UserClaimsDesc, prometheus.GaugeValue, float64(count), key[0], key[1])
+	}
+	for key, quota := range requested {
+		collectQuota(ch, quotaRequestedDesc, key[0], key[1], quota)
+	}
+}
+
+func (c *collector) collectS3Buckets(ctx context.Context, ch chan<- prometheus.Metric) {
+	s3BucketList := &s3v1alpha1.S3BucketList{}
+	if err := c.reader.List(ctx, s3BucketList); err != nil {
+		c.logger.Error(err, "failed to list s3Buckets")
+		return
+	}
+	counts := map[[2]string]int{}
+	for _, s3Bucket := range s3BucketList.Items {
+		counts[[2]string{s3Bucket.Namespace, readyStatus(s3Bucket.Status.Conditions)}]++
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(s3BucketsDesc, prometheus.GaugeValue, float64(count), key[0], key[1])
+	}
+}
+
+// collectNamespaceQuotas collects the lowest hard limit of the ResourceQuotas of each namespace, which is the limit
+// the webhook enforces
+func (c *collector) collectNamespaceQuotas(ctx context.Context, ch chan<- prometheus.Metric) {
+	resourceQuotaList := &corev1.ResourceQuotaList{}
+	if err := c.reader.List(ctx, resourceQuotaList); err != nil {
+		c.logger.Error(err, "failed to list resource quotas")
+		return
+	}
+	allowed := map[string]corev1.ResourceList{}
+	for _, resourceQuota := range resourceQuotaList.Items {
+		for _, resourceName := range quotaResourceNames {
+			hard, ok := resourceQuota.Spec.Hard[resourceName]
+			if !ok {
+				continue
+			}
+			if allowed[resourceQuota.Namespace] == nil {
+				allowed[resourceQuota.Namespace] = corev1.ResourceList{}
+			}
+			if current, ok := allowed[resourceQuota.Namespace][resourceName]; !ok || hard.Cmp(current) < 0 {
+				allowed[resourceQuota.Namespace][resourceName] = hard
+			}
+		}
+	}
+	for namespace, hard := range allowed {
+		collectResourceList(ch, quotaAllowedDesc, scopeNamespace, namespace, hard)
+	}
+}
+
+func (c *collector) collectTeamQuotas(ctx context.Context, ch chan<- prometheus.Metric) {
+	teamQuotas, err := s3v1alpha1.ListTeamQuotas(ctx, c.reader)
+	if err != nil {
+		c.logger.Error(err, "failed to list team quotas")
+		return
+	}
+	for _, teamQuota := range teamQuotas {
+		collectResourceList(ch, quotaAllowedDesc, scopeTeam, teamQuota.Name, teamQuota.Hard)
+	}
+}
+
+var quotaResourceNames = []corev1.ResourceName{
+	consts.ResourceNameS3MaxSize, consts.ResourceNameS3MaxObjects, consts.ResourceNameS3MaxBuckets,
+}
+
+func readyStatus(conditions []metav1.Condition) string {
+	if condition := meta.FindStatusCondition(conditions, consts.ConditionTypeReady); condition != nil {
+		return string(condition.Status)
+	}
+	return string(metav1.ConditionUnknown)
+}
+
+func addQuota(quotas map[[2]string]*s3v1alpha1.UserQuota, key [2]string, quota *s3v1alpha1.UserQuota) {
+	total, ok := quotas[key]
+	if !ok {
+		total = &s3v1alpha1.UserQuota{}
+		quotas[key] = total
+	}
+	total.MaxSize.Add(quota.MaxSize)
+	total.MaxObjects.Add(quota.MaxObjects)
+	total.MaxBuckets += quota.MaxBuckets
+}
+
+func collectQuota(ch chan<- prometheus.Metric, desc *prometheus.Desc, scope, name string, quota *s3v1alpha1.UserQuota) {
+	collectResourceList(ch, desc, scope, name, corev1.ResourceList{
+		consts.ResourceNameS3MaxSize:    quota.MaxSize,
+		consts.ResourceNameS3MaxObjects: quota.MaxObjects,
+		consts.ResourceNameS3MaxBuckets: *resource.NewQuantity(quota.MaxBuckets, resource.DecimalSI),
+	})
+}
+
+func collectResourceList(ch chan<- prometheus.Metric, desc *prometheus.Desc, scope, name string,
+	resourceList corev1.ResourceList) {
+	for _, resourceName := range quotaResourceNames {
+		if quantity, ok := resourceList[resourceName]; ok {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, quantity.AsApproximateFloat64(),
+				scope, name, string(resourceName))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "ceph_s3_operator"
+
+	// APIAdmin and APIS3 are the APIs of RGW which the requests are sent to
+	APIAdmin = "admin"
+	APIS3    = "s3"
+
+	// codeNetwork is the code of the requests which failed without a response
+	codeNetwork = "network"
+)
+
+var (
+	rgwRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rgw_request_duration_seconds",
+		Help:      "Duration of the requests to RGW including their retries, by API and operation",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"api", "operation"})
+
+	rgwRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rgw_request_errors_total",
+		Help:      "Number of the requests to RGW which failed after their retries, by API, operation and status code",
+	}, []string{"api", "operation", "code"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(rgwRequestDuration, rgwRequestErrors)
+}
+
+// ObserveRgwRequest records the duration of a request to RGW and counts it as failed if it got an error status code
+// or failed without a response
+func ObserveRgwRequest(api, operation string, duration time.Duration, resp *http.Response, err error) {
+	rgwRequestDuration.WithLabelValues(api, operation).Observe(duration.Seconds())
+	switch {
+	case resp != nil && resp.StatusCode >= http.StatusBadRequest:
+		rgwRequestErrors.WithLabelValues(api, operation, strconv.Itoa(resp.StatusCode)).Inc()
+	case err != nil:
+		rgwRequestErrors.WithLabelValues(api, operation, codeNetwork).Inc()
+	}
+}
+
+// AdminOperation returns the name of the go-ceph method which sent the request to the RGW admin API, e.g. GetUser or
+// SetUserQuota. The method and the path are returned for the requests which aren't recognized.
+func AdminOperation(req *http.Request) string {
+	path := req.URL.Path
+	if i := strings.LastIndex(path, "/admin/"); i >= 0 {
+		path = path[i+len("/admin"):]
+	}
+	if operation := adminOperation(req.Method, path, req.URL.Query()); operation != "" {
+		return operation
+	}
+	return req.Method + " " + path
+}
+
+func adminOperation(method, path string, query url.Values) string {
+	has := func(key string) bool {
+		_, ok := query[key]
+		return ok
+	}
+	byMethod := func(get, put, post, del string) string {
+		return map[string]string{
+			http.MethodGet:    get,
+			http.MethodPut:    put,
+			http.MethodPost:   post,
+			http.MethodDelete: del,
+		}[method]
+	}
+
+	switch path {
+	case "/user":
+		switch {
+		case has("quota"):
+			return byMethod("GetUserQuota", "SetUserQuota", "", "")
+		case has("key"):
+			return byMethod("", "CreateKey", "", "RemoveKey")
+		case has("caps"):
+			return byMethod("", "AddUserCap", "", "RemoveUserCap")
+		case has("subuser"):
+			return byMethod("", "CreateSubuser", "ModifySubuser", "RemoveSubuser")
+		}
+		return byMethod("GetUser", "CreateUser", "ModifyUser", "RemoveUser")
+	case "/metadata/user":
+		return byMethod("GetUsers", "", "", "")
+	case "/bucket":
+		switch {
+		case has("quota"):
+			return byMethod("", "SetIndividualBucketQuota", "", "")
+		case method == http.MethodGet && has("bucket"):
+			return "GetBucketInfo"
+		case method == http.MethodGet && has("uid"):
+			return "ListUsersBuckets"
+		}
+		return byMethod("ListBuckets", "LinkBucket", "UnlinkBucket", "RemoveBucket")
+	case "/usage":
+		return byMethod("GetUsage", "", "", "TrimUsage")
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Metrics", func() {
+	DescribeTable("Should name the operation of a request to the RGW admin API",
+		func(method, target, operation string) {
+			req := httptest.NewRequest(method, "http://rgw.example.com"+target, nil)
+			Expect(AdminOperation(req)).To(Equal(operation))
+		},
+		Entry("GetUser", http.MethodGet, "/admin/user?format=json&uid=tenant%24user", "GetUser"),
+		Entry("SetUserQuota", http.MethodPut, "/admin/user?quota&format=json&uid=tenant%24user&quota-type=user",
+			"SetUserQuota"),
+		Entry("CreateKey", http.MethodPut, "/admin/user?key&format=json&uid=tenant%24user", "CreateKey"),
+		Entry("an endpoint behind a path prefix", http.MethodGet, "/rgw/admin/user?uid=user", "GetUser"),
+		Entry("an unknown path", http.MethodGet, "/admin/info?format=json", "GET /info"),
+		Entry("an unknown method of a known path", http.MethodPatch, "/admin/user?uid=user", "PATCH /user"),
+	)
+
+	Context("When observing a request to RGW", func() {
+		errorsOf := func(operation, code string) float64 {
+			return testutil.ToFloat64(rgwRequestErrors.WithLabelValues(APIAdmin, operation, code))
+		}
+
+		It("Should count the requests failed with an error status code by their code", func() {
+			ObserveRgwRequest(APIAdmin, "TestNotFound", time.Second, &http.Response{StatusCode: http.StatusNotFound},
+				nil)
+			Expect(errorsOf("TestNotFound", "404")).To(Equal(1.0))
+		})
+
+		It("Should count the requests failed without a response as network errors", func() {
+			ObserveRgwRequest(APIAdmin, "TestNetwork", time.Second, nil, errors.New("connection refused"))
+			Expect(errorsOf("TestNetwork", codeNetwork)).To(Equal(1.0))
+		})
+
+		It("Should only record the duration of the successful requests", func() {
+			errorSeries := testutil.CollectAndCount(rgwRequestErrors)
+			durationSeries := testutil.CollectAndCount(rgwRequestDuration)
+			ObserveRgwRequest(APIAdmin, "TestSuccess", time.Second, &http.Response{StatusCode: http.StatusOK}, nil)
+			Expect(testutil.CollectAndCount(rgwRequestErrors)).To(Equal(errorSeries))
+			Expect(testutil.CollectAndCount(rgwRequestDuration)).To(Equal(durationSeries + 1))
+		})
+	})
+})
@@ -1,6 +1,8 @@
 package predicates
 
 import (
+	"context"
+
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 )
@@ -9,13 +11,18 @@ type S3UserClassBased interface {
 	GetS3UserClass() string
 }
 
+// S3UserClassHandler reports whether an S3UserClass is served by the operator
+type S3UserClassHandler interface {
+	Handles(ctx context.Context, s3UserClass string) bool
+}
+
 type S3ClassPredicate struct {
-	S3UserClass string
+	handler S3UserClassHandler
 }
 
-func NewS3ClassPredicate(s3UserClass string) S3ClassPredicate {
+func NewS3ClassPredicate(handler S3UserClassHandler) S3ClassPredicate {
 	return S3ClassPredicate{
-		S3UserClass: s3UserClass,
+		handler: handler,
 	}
 }
 
@@ -24,7 +31,7 @@ func (scp S3ClassPredicate) MatchesS3UserClass(obj client.Object) bool {
 	if !ok {
 		return false
 	}
-	return s3UserClassBased.GetS3UserClass() == scp.S3UserClass
+	return scp.handler.Handles(context.Background(), s3UserClassBased.GetS3UserClass())
 }
 
 func (scp S3ClassPredicate) Create(e event.CreateEvent) bool {
@@ -4,14 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"k8s.io/apimachinery/pkg/runtime"
 
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/metrics"
 	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
 )
 
@@ -20,32 +26,31 @@ type S3Agent struct {
 	Client *s3.S3
 }
 
-func NewS3Agent(accessKey, secretKey, endpoint string, debug bool) (*S3Agent, error) {
-	// TODO: cephRegion must not be hardcoded.
-	const cephRegion = "us-east-1"
-
+// NewS3Agent returns an agent of the S3 API at the endpoint. The scheme of the endpoint decides whether TLS is used,
+// which is configured by the HTTP client. The default HTTP client is used if it's nil.
+func NewS3Agent(accessKey, secretKey, endpoint, region string, httpClient *http.Client, maxRetries int,
+	debug bool) (*S3Agent, error) {
 	logLevel := aws.LogOff
 	if debug {
 		logLevel = aws.LogDebug
 	}
-	client := http.Client{
-		Timeout: time.Second * 15,
-	}
 	sess, err := session.NewSession(
 		aws.NewConfig().
-			WithRegion(cephRegion).
+			WithRegion(region).
 			WithCredentials(credentials.NewStaticCredentials(accessKey, secretKey, "")).
 			WithEndpoint(endpoint).
 			WithS3ForcePathStyle(true).
-			WithMaxRetries(5).
-			WithDisableSSL(true).
-			WithHTTPClient(&client).
+			WithMaxRetries(maxRetries).
+			WithHTTPClient(httpClient).
 			WithLogLevel(logLevel),
 	)
 	if err != nil {
 		return nil, err
 	}
 	svc := s3.New(sess)
+	svc.Handlers.Complete.PushBack(func(req *request.Request) {
+		metrics.ObserveRgwRequest(metrics.APIS3, req.Operation.Name, time.Since(req.Time), req.HTTPResponse, req.Error)
+	})
 	return &S3Agent{
 		Client: svc,
 	}, nil
@@ -78,44 +83,197 @@ func (s *S3Agent) DeleteBucket(name string) error {
 	return err
 }
 
-func (s *S3Agent) SetBucketPolicy(subuserAccessMap map[string]string, tenant string,
-	owner string, bucket string) (string, error) {
-	// The map of access levels to the AWS IAM names slice
-	accessAWSIAMMap := make(map[string][]string)
+// GetBucketVersioning returns the versioning state of the bucket, which is empty if versioning was never enabled
+func (s *S3Agent) GetBucketVersioning(bucket string) (string, error) {
+	output, err := s.Client.GetBucketVersioning(&s3.GetBucketVersioningInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(output.Status), nil
+}
+
+func (s *S3Agent) SetBucketVersioning(bucket, status string) error {
+	_, err := s.Client.PutBucketVersioning(&s3.PutBucketVersioningInput{
+		Bucket:                  aws.String(bucket),
+		VersioningConfiguration: &s3.VersioningConfiguration{Status: aws.String(status)},
+	})
+	return err
+}
+
+// SetBucketLifecycle replaces the lifecycle configuration of the bucket. The configuration is removed if there's no rule.
+func (s *S3Agent) SetBucketLifecycle(bucket string, lifecycle *s3v1alpha1.BucketLifecycle) error {
+	if lifecycle == nil || len(lifecycle.Rules) == 0 {
+		_, err := s.Client.DeleteBucketLifecycle(&s3.DeleteBucketLifecycleInput{Bucket: aws.String(bucket)})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchLifecycleConfiguration" {
+			return nil
+		}
+		return err
+	}
+
+	rules := make([]*s3.LifecycleRule, 0, len(lifecycle.Rules))
+	for _, rule := range lifecycle.Rules {
+		rules = append(rules, generateLifecycleRule(rule))
+	}
+	_, err := s.Client.PutBucketLifecycleConfiguration(&s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(bucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: rules},
+	})
+	return err
+}
+
+// SetBucketCORS replaces the CORS configuration of the bucket. The configuration is removed if there's no rule.
+func (s *S3Agent) SetBucketCORS(bucket string, cors *s3v1alpha1.BucketCORS) error {
+	if cors == nil || len(cors.Rules) == 0 {
+		_, err := s.Client.DeleteBucketCors(&s3.DeleteBucketCorsInput{Bucket: aws.String(bucket)})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NoSuchCORSConfiguration" {
+			return nil
+		}
+		return err
+	}
+
+	rules := make([]*s3.CORSRule, 0, len(cors.Rules))
+	for _, rule := range cors.Rules {
+		corsRule := &s3.CORSRule{
+			AllowedOrigins: aws.StringSlice(rule.AllowedOrigins),
+			MaxAgeSeconds:  rule.MaxAgeSeconds,
+		}
+		for _, method := range rule.AllowedMethods {
+			corsRule.AllowedMethods = append(corsRule.AllowedMethods, aws.String(string(method)))
+		}
+		if len(rule.AllowedHeaders) > 0 {
+			corsRule.AllowedHeaders = aws.StringSlice(rule.AllowedHeaders)
+		}
+		if len(rule.ExposeHeaders) > 0 {
+			corsRule.ExposeHeaders = aws.StringSlice(rule.ExposeHeaders)
+		}
+		rules = append(rules, corsRule)
+	}
+	_, err := s.Client.PutBucketCors(&s3.PutBucketCorsInput{
+		Bucket:            aws.String(bucket),
+		CORSConfiguration: &s3.CORSConfiguration{CORSRules: rules},
+	})
+	return err
+}
+
+// BucketGrant grants access on a bucket to the principal of a Ceph user or subuser
+type BucketGrant struct {
+	Principal string
+	Access    string
+	Prefixes  []string
+}
+
+// UserPrincipal returns the principal of the Ceph user, or of its subuser if it's given, in bucket policies
+func UserPrincipal(tenant, userId, subuser string) string {
+	principal := fmt.Sprintf("arn:aws:iam::%s:user/%s", tenant, userId)
+	if subuser != "" {
+		principal += ":" + subuser
+	}
+	return principal
+}
+
+// SetBucketPolicy sets the policy of the bucket made of the statements generated for the subuser bindings, the
+// grants and the public access, followed by the additional statements in their order
+func (s *S3Agent) SetBucketPolicy(subuserBindings []s3v1alpha1.SubuserBinding, grants []BucketGrant,
+	publicAccess *s3v1alpha1.BucketPublicAccess, additionalStatements []runtime.RawExtension,
+	tenant string, owner string, bucket string) (string, error) {
+	for _, binding := range subuserBindings {
+		grants = append(grants, BucketGrant{
+			Principal: UserPrincipal(tenant, owner, binding.Name),
+			Access:    binding.Access,
+			Prefixes:  binding.Prefixes,
+		})
+	}
+
+	// The map of the access scopes to the AWS IAM names slice. Principals with the same access level and prefixes
+	// share the statements of their scope.
+	scopeAWSIAMMap := make(map[string][]string)
+	scopes := make(map[string]BucketGrant)
 	policy := map[string]interface{}{
 		"Version": "2012-10-17",
 		"Id":      "S3Policy",
 	}
 	statementSlice := []map[string]interface{}{}
-	for subuser, access := range subuserAccessMap {
-		// Create AWS IAM Name needed for the policy from the subuser name
-		aws_iam := fmt.Sprintf("arn:aws:iam::%s:user/%s:%s", tenant, owner, subuser)
-		accessAWSIAMMap[access] = append(accessAWSIAMMap[access], aws_iam)
-	}
-
-	// Iterate over different levels
-	for access, AWS_iam := range accessAWSIAMMap {
-		principal := map[string][]string{}
-		statement := map[string]interface{}{
-			"Sid":       "BucketAllow",
-			"Effect":    "Allow",
-			"Principal": map[string][]string{},
-			"Action":    []string{},
-			"Resource": []string{
-				fmt.Sprintf("arn:aws:s3::%s:%s", tenant, bucket),
-				fmt.Sprintf("arn:aws:s3::%s:%s/*", tenant, bucket),
-			},
-		}
-		principal["AWS"] = AWS_iam
-		statement["Principal"] = principal
-
-		bucketAccessAction := generateBucketAccessAction()
-		if actions, exists := bucketAccessAction[access]; exists {
-			statement["Action"] = actions
-		} else {
-			return "", fmt.Errorf("the access %s doesn't exists", access)
+	for _, grant := range grants {
+		prefixes := append([]string{}, grant.Prefixes...)
+		sort.Strings(prefixes)
+		scope := grant.Access + "|" + strings.Join(prefixes, "|")
+		scopeAWSIAMMap[scope] = append(scopeAWSIAMMap[scope], grant.Principal)
+		scopes[scope] = BucketGrant{Access: grant.Access, Prefixes: prefixes}
+	}
+
+	// Sort the scopes to generate the same policy on every reconcile
+	scopeKeys := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		scopeKeys = append(scopeKeys, scope)
+	}
+	sort.Strings(scopeKeys)
+
+	// Iterate over different scopes
+	bucketAccessAction := generateBucketAccessAction()
+	for _, scope := range scopeKeys {
+		AWS_iam := scopeAWSIAMMap[scope]
+		sort.Strings(AWS_iam)
+		principal := map[string][]string{"AWS": AWS_iam}
+
+		actions, exists := bucketAccessAction[scopes[scope].Access]
+		if !exists {
+			return "", fmt.Errorf("the access %s doesn't exists", scopes[scope].Access)
+		}
+		prefixes := scopes[scope].Prefixes
+		if len(prefixes) == 0 {
+			statementSlice = append(statementSlice, map[string]interface{}{
+				"Sid":       "BucketAllow",
+				"Effect":    "Allow",
+				"Principal": principal,
+				"Action":    actions,
+				"Resource": []string{
+					fmt.Sprintf("arn:aws:s3::%s:%s", tenant, bucket),
+					fmt.Sprintf("arn:aws:s3::%s:%s/*", tenant, bucket),
+				},
+			})
+			continue
+		}
+
+		// Scope the listing by the s3:prefix condition and the object actions by the object resources
+		bucketActions, objectActions := splitBucketAccessAction(actions)
+		if len(bucketActions) > 0 {
+			listPrefixes := make([]string, 0, len(prefixes))
+			for _, prefix := range prefixes {
+				listPrefixes = append(listPrefixes, prefix+"*")
+			}
+			statementSlice = append(statementSlice, map[string]interface{}{
+				"Sid":       "BucketAllow",
+				"Effect":    "Allow",
+				"Principal": principal,
+				"Action":    bucketActions,
+				"Resource":  []string{fmt.Sprintf("arn:aws:s3::%s:%s", tenant, bucket)},
+				"Condition": map[string]interface{}{
+					"StringLike": map[string][]string{"s3:prefix": listPrefixes},
+				},
+			})
+		}
+		if len(objectActions) > 0 {
+			resources := make([]string, 0, len(prefixes))
+			for _, prefix := range prefixes {
+				resources = append(resources, fmt.Sprintf("arn:aws:s3::%s:%s/%s*", tenant, bucket, prefix))
+			}
+			statementSlice = append(statementSlice, map[string]interface{}{
+				"Sid":       "ObjectAllow",
+				"Effect":    "Allow",
+				"Principal": principal,
+				"Action":    objectActions,
+				"Resource":  resources,
+			})
+		}
+	}
+	if publicAccess.IsEnabled() {
+		statementSlice = append(statementSlice, generatePublicAccessStatement(publicAccess, tenant, bucket))
+	}
+	for i, rawStatement := range additionalStatements {
+		statement := map[string]interface{}{}
+		if err := json.Unmarshal(rawStatement.Raw, &statement); err != nil {
+			return "", fmt.Errorf("failed to parse the additional policy statement %d, %w", i, err)
 		}
-		// Append the statement
 		statementSlice = append(statementSlice, statement)
 	}
 	policy["Statement"] = statementSlice
@@ -133,18 +291,114 @@ func (s *S3Agent) SetBucketPolicy(subuserAccessMap map[string]string, tenant str
 	return string(policyMarshal), nil
 }
 
+// generatePublicAccessStatement generates the statement which allows anonymous users to get the objects of the bucket,
+// or the objects under the prefixes in the read-prefixes mode
+func generatePublicAccessStatement(publicAccess *s3v1alpha1.BucketPublicAccess, tenant, bucket string) map[string]interface{} {
+	resources := []string{fmt.Sprintf("arn:aws:s3::%s:%s/*", tenant, bucket)}
+	if publicAccess.Mode == consts.PublicAccessModeReadPrefixes {
+		prefixes := append([]string{}, publicAccess.Prefixes...)
+		sort.Strings(prefixes)
+		resources = make([]string, 0, len(prefixes))
+		for _, prefix := range prefixes {
+			resources = append(resources, fmt.Sprintf("arn:aws:s3::%s:%s/%s*", tenant, bucket, prefix))
+		}
+	}
+	return map[string]interface{}{
+		"Sid":       "PublicRead",
+		"Effect":    "Allow",
+		"Principal": map[string][]string{"AWS": {"*"}},
+		"Action":    []string{"s3:GetObject"},
+		"Resource":  resources,
+	}
+}
+
+func generateLifecycleRule(rule s3v1alpha1.LifecycleRule) *s3.LifecycleRule {
+	status := s3.ExpirationStatusEnabled
+	if rule.Disabled {
+		status = s3.ExpirationStatusDisabled
+	}
+	lifecycleRule := &s3.LifecycleRule{
+		ID:     aws.String(rule.ID),
+		Status: aws.String(status),
+		Filter: generateLifecycleFilter(rule.Filter),
+	}
+	if rule.ExpirationDays != nil {
+		lifecycleRule.Expiration = &s3.LifecycleExpiration{Days: rule.ExpirationDays}
+	}
+	if rule.NoncurrentVersionExpirationDays != nil {
+		lifecycleRule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{
+			NoncurrentDays: rule.NoncurrentVersionExpirationDays,
+		}
+	}
+	if rule.AbortIncompleteMultipartUploadDays != nil {
+		lifecycleRule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: rule.AbortIncompleteMultipartUploadDays,
+		}
+	}
+	for _, transition := range rule.Transitions {
+		lifecycleRule.Transitions = append(lifecycleRule.Transitions, &s3.Transition{
+			Days:         aws.Int64(transition.Days),
+			StorageClass: aws.String(transition.StorageClass),
+		})
+	}
+	return lifecycleRule
+}
+
+func generateLifecycleFilter(filter *s3v1alpha1.LifecycleFilter) *s3.LifecycleRuleFilter {
+	if filter == nil {
+		return &s3.LifecycleRuleFilter{Prefix: aws.String("")}
+	}
+
+	// Sort the tags to generate the same configuration on every reconcile
+	tagKeys := make([]string, 0, len(filter.Tags))
+	for key := range filter.Tags {
+		tagKeys = append(tagKeys, key)
+	}
+	sort.Strings(tagKeys)
+	tags := make([]*s3.Tag, 0, len(tagKeys))
+	for _, key := range tagKeys {
+		tags = append(tags, &s3.Tag{Key: aws.String(key), Value: aws.String(filter.Tags[key])})
+	}
+
+	switch {
+	case len(tags) == 0:
+		return &s3.LifecycleRuleFilter{Prefix: aws.String(filter.Prefix)}
+	case len(tags) == 1 && filter.Prefix == "":
+		return &s3.LifecycleRuleFilter{Tag: tags[0]}
+	default:
+		return &s3.LifecycleRuleFilter{And: &s3.LifecycleRuleAndOperator{
+			Prefix: aws.String(filter.Prefix),
+			Tags:   tags,
+		}}
+	}
+}
+
 func generateBucketAccessAction() map[string][]string {
-	readActions := []string{
+	listActions := []string{
 		"s3:ListBucket",
-		"s3:GetObject",
 	}
+	readActions := append(listActions, "s3:GetObject")
 	writeActions := []string{
 		"s3:DeleteObject",
 		"s3:PutObject",
 	}
 
 	return map[string][]string{
-		consts.BucketAccessRead:  readActions,
-		consts.BucketAccessWrite: append(readActions, writeActions...),
+		consts.BucketAccessRead:      readActions,
+		consts.BucketAccessWrite:     append(append([]string{}, readActions...), writeActions...),
+		consts.BucketAccessList:      listActions,
+		consts.BucketAccessWriteOnly: {"s3:PutObject"},
+	}
+}
+
+// splitBucketAccessAction splits the actions into the ones on the bucket and the ones on its objects
+func splitBucketAccessAction(actions []string) (bucketActions, objectActions []string) {
+	for _, action := range actions {
+		if action == "s3:ListBucket" {
+			bucketActions = append(bucketActions, action)
+		} else {
+			objectActions = append(objectActions, action)
+		}
 	}
+	return bucketActions, objectActions
 }
@@ -0,0 +1,85 @@
+package s3userclass
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+// classClients are the clients of a class. They're shared by all the reconciles of the class so that the connections
+// to RGW are reused, and they're rebuilt only when the config or the certificates of the class change.
+type classClients struct {
+	// key identifies the config which the clients are built from
+	key        string
+	tlsConfig  *tls.Config
+	httpClient *http.Client
+	rgwClient  *admin.API
+	rgwErr     error
+}
+
+// clientCache holds the clients of each class by its name
+type clientCache struct {
+	mu      sync.Mutex
+	clients map[string]*classClients
+}
+
+// sharedClients is shared by the resolvers of all the controllers
+var sharedClients = &clientCache{clients: map[string]*classClients{}}
+
+// attach sets the clients of the class, building them if the class is new or its config has changed since they were
+// built. The idle connections of the replaced clients are closed.
+func (cc *clientCache) attach(c *Class, material tlsMaterial) error {
+	key := clientsKey(c, material)
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	clients, found := cc.clients[c.Name]
+	if !found || clients.key != key {
+		tlsConfig, err := newTLSConfig(material)
+		if err != nil {
+			return err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		httpClient := &http.Client{Transport: transport, Timeout: c.Timeout}
+		rgwClient, rgwErr := admin.New(c.Endpoint, c.AccessKey, c.SecretKey,
+			&retryingClient{client: httpClient, maxRetries: c.MaxRetries})
+
+		if found {
+			clients.httpClient.CloseIdleConnections()
+		}
+		clients = &classClients{
+			key:        key,
+			tlsConfig:  tlsConfig,
+			httpClient: httpClient,
+			rgwClient:  rgwClient,
+			rgwErr:     rgwErr,
+		}
+		cc.clients[c.Name] = clients
+	}
+
+	c.TLSConfig = clients.tlsConfig
+	c.clients = clients
+	return nil
+}
+
+// clientsKey hashes everything the clients of the class are built from, so that the secrets aren't kept as keys
+func clientsKey(c *Class, material tlsMaterial) string {
+	hash := sha256.New()
+	for _, field := range [][]byte{
+		[]byte(c.Endpoint), []byte(c.AccessKey), []byte(c.SecretKey), []byte(c.Timeout.String()),
+		[]byte(strconv.Itoa(c.MaxRetries)), []byte(strconv.FormatBool(material.insecureSkipVerify)),
+		material.caBundle, material.certPEM, material.keyPEM,
+	} {
+		// The length prefix keeps the fields apart
+		fmt.Fprintf(hash, "%d:", len(field))
+		hash.Write(field)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
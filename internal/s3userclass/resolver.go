@@ -0,0 +1,239 @@
+package s3userclass
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/config"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/s3_agent"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// Class is a resolved S3UserClass, holding everything needed to talk to its Ceph cluster
+type Class struct {
+	Name        string
+	ClusterName string
+	Endpoint    string
+	Region      string
+	AccessKey   string
+	SecretKey   string
+	// PublicAccess restricts the namespaces whose buckets can be public
+	PublicAccess *s3v1alpha1.PublicAccessPolicy
+	// Adoption restricts the namespaces which can adopt the users and import the buckets of other tenants
+	Adoption *s3v1alpha1.AdoptionPolicy
+	// TLSConfig, Timeout and MaxRetries apply to both the RGW admin API and the S3 API
+	TLSConfig  *tls.Config
+	Timeout    time.Duration
+	MaxRetries int
+
+	clients *classClients
+}
+
+// Tenant returns the Ceph tenant of the given namespace in the cluster of the class
+func (c *Class) Tenant(namespace string) string {
+	return s3v1alpha1.CephTenant(c.ClusterName, namespace)
+}
+
+// AllowsAdoption reports whether the namespace can adopt the users and import the buckets of the tenant. The tenant
+// of the namespace is always allowed, other tenants only if the adoption policy of the class allows the namespace.
+func (c *Class) AllowsAdoption(namespace, tenant string) bool {
+	return tenant == c.Tenant(namespace) || c.Adoption.Allows(namespace)
+}
+
+// CephUser returns the tenant and the ID of the Ceph user of a claim. A claim adopting an existing user is bound to
+// that user, otherwise the user is named after the claim in the tenant of its namespace.
+func (c *Class) CephUser(namespace, claimName, existingUser string) (tenant, userId string) {
+	if existingUser != "" {
+		return SplitUserID(existingUser)
+	}
+	return c.Tenant(namespace), claimName
+}
+
+// S3UserCephUserID returns the full ID of the Ceph user which the S3User of the class is bound to. S3Users created
+// before the Ceph user ID was recorded are bound to the user named after their claim.
+func (c *Class) S3UserCephUserID(s3User *s3v1alpha1.S3User) string {
+	if s3User.Spec.CephUserID == "" && s3User.Spec.ClaimRef != nil {
+		return FullUserID(c.Tenant(s3User.Spec.ClaimRef.Namespace), s3User.Spec.ClaimRef.Name)
+	}
+	return s3User.Spec.CephUserID
+}
+
+// SplitUserID splits the full ID of a Ceph user into its tenant and ID. The tenant of a user without tenant is empty.
+func SplitUserID(fullId string) (tenant, userId string) {
+	if tenant, userId, found := strings.Cut(fullId, "$"); found {
+		return tenant, userId
+	}
+	return "", fullId
+}
+
+// FullUserID joins the tenant and the ID of a Ceph user the way the RGW admin API expects
+func FullUserID(tenant, userId string) string {
+	if tenant == "" {
+		return userId
+	}
+	return fmt.Sprintf("%s$%s", tenant, userId)
+}
+
+// HTTPClient returns the HTTP client which connects to RGW with the TLS config and the timeout of the class. The
+// client is shared by all the users of the class.
+func (c *Class) HTTPClient() *http.Client {
+	return c.clients.httpClient
+}
+
+// RgwClient returns the client of the RGW admin API of the class, which is shared by all the users of the class
+func (c *Class) RgwClient() (*admin.API, error) {
+	return c.clients.rgwClient, c.clients.rgwErr
+}
+
+// NewS3Agent returns a client of the S3 API of the class with the keys of a user
+func (c *Class) NewS3Agent(accessKey, secretKey string) (*s3_agent.S3Agent, error) {
+	return s3_agent.NewS3Agent(accessKey, secretKey, c.Endpoint, c.Region, c.HTTPClient(), c.MaxRetries, true)
+}
+
+// Resolver resolves the S3UserClass of claims and buckets. The class named in the operator config is served
+// by the rgw section of the config unless an S3UserClass object with the same name exists.
+type Resolver struct {
+	reader       client.Reader
+	defaultClass Class
+	defaultTLS   *config.TLS
+}
+
+func NewResolver(reader client.Reader, cfg *config.Config) *Resolver {
+	return &Resolver{
+		reader: reader,
+		defaultClass: Class{
+			Name:        cfg.S3UserClass,
+			ClusterName: cfg.ClusterName,
+			Endpoint:    cfg.Rgw.Endpoint,
+			Region:      cfg.Rgw.Region,
+			AccessKey:   cfg.Rgw.AccessKey,
+			SecretKey:   cfg.Rgw.SecretKey,
+			PublicAccess: &s3v1alpha1.PublicAccessPolicy{
+				AllowedNamespaces: cfg.PublicAccess.AllowedNamespaces,
+			},
+			Adoption: &s3v1alpha1.AdoptionPolicy{
+				AllowedNamespaces: cfg.Adoption.AllowedNamespaces,
+			},
+			Timeout:    time.Duration(cfg.Rgw.TimeoutSeconds) * time.Second,
+			MaxRetries: cfg.Rgw.MaxRetries,
+		},
+		defaultTLS: cfg.Rgw.TLS,
+	}
+}
+
+// Name returns the name of the class, replacing the empty name with the default class
+func (r *Resolver) Name(s3UserClass string) string {
+	if s3UserClass == "" {
+		return r.defaultClass.Name
+	}
+	return s3UserClass
+}
+
+// Handles reports whether the class is served by the operator
+func (r *Resolver) Handles(ctx context.Context, s3UserClass string) bool {
+	name := r.Name(s3UserClass)
+	if name == r.defaultClass.Name {
+		return true
+	}
+	return r.reader.Get(ctx, types.NamespacedName{Name: name}, &s3v1alpha1.S3UserClass{}) == nil
+}
+
+func (r *Resolver) Resolve(ctx context.Context, s3UserClass string) (*Class, error) {
+	name := r.Name(s3UserClass)
+
+	s3UserClassObj := &s3v1alpha1.S3UserClass{}
+	switch err := r.reader.Get(ctx, types.NamespacedName{Name: name}, s3UserClassObj); {
+	case apierrors.IsNotFound(err):
+		if name == r.defaultClass.Name {
+			defaultClass := r.defaultClass
+			material, err := loadTLSMaterial(r.defaultTLS)
+			if err == nil {
+				err = sharedClients.attach(&defaultClass, material)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the TLS config of s3UserClass %s, %w", name, err)
+			}
+			return &defaultClass, nil
+		}
+		return nil, fmt.Errorf("%w, s3UserClass=%s", consts.ErrS3UserClassNotFound, name)
+	case err != nil:
+		return nil, fmt.Errorf("failed to get s3UserClass, %w", err)
+	}
+
+	secretRef := s3UserClassObj.Spec.AdminSecretRef
+	adminSecret := &corev1.Secret{}
+	if err := r.reader.Get(ctx, types.NamespacedName{Namespace: secretRef.Namespace, Name: secretRef.Name}, adminSecret); err != nil {
+		return nil, fmt.Errorf("failed to get admin secret of s3UserClass %s, %w", name, err)
+	}
+
+	material, err := r.tlsMaterial(ctx, s3UserClassObj.Spec.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TLS config of s3UserClass %s, %w", name, err)
+	}
+	timeout := time.Duration(config.DefaultConfig.Rgw.TimeoutSeconds) * time.Second
+	if s3UserClassObj.Spec.Timeout != nil {
+		timeout = s3UserClassObj.Spec.Timeout.Duration
+	}
+	maxRetries := config.DefaultConfig.Rgw.MaxRetries
+	if s3UserClassObj.Spec.MaxRetries != nil {
+		maxRetries = int(*s3UserClassObj.Spec.MaxRetries)
+	}
+
+	class := &Class{
+		Name:         name,
+		ClusterName:  s3UserClassObj.Spec.ClusterName,
+		Endpoint:     s3UserClassObj.Spec.Endpoint,
+		Region:       s3UserClassObj.Spec.Region,
+		AccessKey:    string(adminSecret.Data[consts.DataKeyAccessKey]),
+		SecretKey:    string(adminSecret.Data[consts.DataKeySecretKey]),
+		PublicAccess: s3UserClassObj.Spec.PublicAccess,
+		Adoption:     s3UserClassObj.Spec.Adoption,
+		Timeout:      timeout,
+		MaxRetries:   maxRetries,
+	}
+	if err := sharedClients.attach(class, material); err != nil {
+		return nil, fmt.Errorf("failed to load the TLS config of s3UserClass %s, %w", name, err)
+	}
+	return class, nil
+}
+
+// tlsMaterial returns the TLS material of the secrets of an S3UserClass
+func (r *Resolver) tlsMaterial(ctx context.Context, rgwTLS *s3v1alpha1.RgwTLS) (tlsMaterial, error) {
+	if rgwTLS == nil {
+		return tlsMaterial{}, nil
+	}
+	material := tlsMaterial{insecureSkipVerify: rgwTLS.InsecureSkipVerify}
+	if rgwTLS.CASecretRef != nil {
+		caSecret, err := r.getSecret(ctx, rgwTLS.CASecretRef)
+		if err != nil {
+			return tlsMaterial{}, fmt.Errorf("failed to get CA secret, %w", err)
+		}
+		material.caBundle = caSecret.Data[consts.DataKeyCABundle]
+	}
+	if rgwTLS.ClientCertSecretRef != nil {
+		clientCertSecret, err := r.getSecret(ctx, rgwTLS.ClientCertSecretRef)
+		if err != nil {
+			return tlsMaterial{}, fmt.Errorf("failed to get client certificate secret, %w", err)
+		}
+		material.certPEM = clientCertSecret.Data[corev1.TLSCertKey]
+		material.keyPEM = clientCertSecret.Data[corev1.TLSPrivateKeyKey]
+	}
+	return material, nil
+}
+
+func (r *Resolver) getSecret(ctx context.Context, secretRef *corev1.SecretReference) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := r.reader.Get(ctx, types.NamespacedName{Namespace: secretRef.Namespace, Name: secretRef.Name}, secret)
+	return secret, err
+}
@@ -0,0 +1,115 @@
+package s3userclass
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/snapp-incubator/ceph-s3-operator/internal/config"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/metrics"
+	"github.com/snapp-incubator/ceph-s3-operator/pkg/consts"
+)
+
+// tlsMaterial is the configuration and the PEM data which the TLS config of a class is built from
+type tlsMaterial struct {
+	insecureSkipVerify        bool
+	caBundle, certPEM, keyPEM []byte
+}
+
+// newTLSConfig returns the TLS config verifying the certificate of RGW by the CA bundle, or by the system CAs if the
+// bundle is empty, and presenting the client certificate if it's given
+func newTLSConfig(material tlsMaterial) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: material.insecureSkipVerify,
+	}
+	if len(material.caBundle) > 0 {
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(material.caBundle) {
+			return nil, fmt.Errorf("failed to parse the CA bundle, no PEM certificate found")
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if len(material.certPEM) > 0 || len(material.keyPEM) > 0 {
+		clientCert, err := tls.X509KeyPair(material.certPEM, material.keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the client certificate, %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+	return tlsConfig, nil
+}
+
+// loadTLSMaterial reads the TLS material of the files of the operator config. The files are read on each call so
+// that the rotated certificates of mounted secrets are picked up.
+func loadTLSMaterial(cfg *config.TLS) (tlsMaterial, error) {
+	if cfg == nil {
+		return tlsMaterial{}, nil
+	}
+	material := tlsMaterial{insecureSkipVerify: cfg.InsecureSkipVerify}
+	for _, file := range []struct {
+		path string
+		data *[]byte
+	}{{cfg.CAFile, &material.caBundle}, {cfg.CertFile, &material.certPEM}, {cfg.KeyFile, &material.keyPEM}} {
+		if file.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			return tlsMaterial{}, fmt.Errorf("failed to read %s, %w", file.path, err)
+		}
+		*file.data = data
+	}
+	return material, nil
+}
+
+// retryingClient retries the requests which fail on the network or with a server error with an exponential backoff.
+// Only the idempotent requests are retried after a server error, unless the server rejected them by 503.
+type retryingClient struct {
+	client     *http.Client
+	maxRetries int
+}
+
+func (c *retryingClient) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		resp, err := c.client.Do(req)
+		if attempt >= c.maxRetries || !shouldRetry(req, resp, err) {
+			metrics.ObserveRgwRequest(metrics.APIAdmin, metrics.AdminOperation(req), time.Since(start), resp, err)
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(consts.RgwRetryBaseDelay << attempt):
+		case <-req.Context().Done():
+			metrics.ObserveRgwRequest(metrics.APIAdmin, metrics.AdminOperation(req), time.Since(start), nil,
+				req.Context().Err())
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	// A consumed body can't be sent again
+	if req.Context().Err() != nil || (req.Body != nil && req.Body != http.NoBody) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	switch {
+	case resp.StatusCode == http.StatusServiceUnavailable:
+		return true
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return req.Method == http.MethodGet || req.Method == http.MethodHead
+	}
+	return false
+}
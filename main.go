@@ -21,7 +21,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/ceph/go-ceph/rgw/admin"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -37,7 +36,10 @@ import (
 	s3v1alpha1 "github.com/snapp-incubator/ceph-s3-operator/api/v1alpha1"
 	"github.com/snapp-incubator/ceph-s3-operator/internal/config"
 	"github.com/snapp-incubator/ceph-s3-operator/internal/controllers/s3bucket"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/controllers/s3bucketaccess"
 	"github.com/snapp-incubator/ceph-s3-operator/internal/controllers/s3userclaim"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/controllers/usermigration"
+	"github.com/snapp-incubator/ceph-s3-operator/internal/metrics"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -80,6 +82,10 @@ func main() {
 		setupLog.Error(err, "failed to get config")
 		os.Exit(1)
 	}
+	if err := cfg.Validate(); err != nil {
+		setupLog.Error(err, "invalid config")
+		os.Exit(1)
+	}
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
@@ -107,14 +113,23 @@ func main() {
 		os.Exit(1)
 	}
 
-	co, err := admin.New(cfg.Rgw.Endpoint, cfg.Rgw.AccessKey, cfg.Rgw.SecretKey, nil)
-	if err != nil {
-		setupLog.Error(err, "failed to create rgw connection")
-		os.Exit(1)
+	s3v1alpha1.DefaultS3UserClass = cfg.S3UserClass
+	s3v1alpha1.DefaultClusterName = cfg.ClusterName
+	s3v1alpha1.DefaultPublicAccessPolicy.AllowedNamespaces = cfg.PublicAccess.AllowedNamespaces
+	s3v1alpha1.DefaultAdoptionPolicy.AllowedNamespaces = cfg.Adoption.AllowedNamespaces
+	s3v1alpha1.QuotaEnforcement = s3v1alpha1.QuotaEnforcementPolicy{
+		Mode:                    cfg.Quota.Mode,
+		OvercommitRatio:         cfg.Quota.OvercommitRatio,
+		WarningThresholdPercent: int64(cfg.Quota.WarningThresholdPercent),
 	}
+	s3v1alpha1.TeamQuotaBackend = cfg.TeamQuota.Backend
+	s3v1alpha1.TeamLabel = cfg.TeamQuota.TeamLabel
+
+	// Expose the state of the operator objects in addition to the metrics of the controllers
+	metrics.RegisterCollector(mgr.GetClient())
 
 	// Setup S3userclaim operator
-	s3UserClaimReconciler := s3userclaim.NewReconciler(mgr, cfg, co)
+	s3UserClaimReconciler := s3userclaim.NewReconciler(mgr, cfg)
 	if err = s3UserClaimReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "S3UserClaim")
 		os.Exit(1)
@@ -127,6 +142,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup usermigration operator
+	userMigrationReconciler := usermigration.NewReconciler(mgr, cfg)
+	if err = userMigrationReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "UserMigration")
+		os.Exit(1)
+	}
+
+	// Setup s3bucketaccess operator
+	s3BucketAccessReconciler := s3bucketaccess.NewReconciler(mgr, cfg)
+	if err = s3BucketAccessReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "S3BucketAccess")
+		os.Exit(1)
+	}
+
 	// Setup webhooks
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		s3v1alpha1.ValidationTimeout = time.Duration(cfg.ValidationWebhookTimeoutSeconds) * time.Second
@@ -139,6 +168,16 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "S3Bucket")
 			os.Exit(1)
 		}
+
+		if err = (&s3v1alpha1.UserMigration{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "UserMigration")
+			os.Exit(1)
+		}
+
+		if err = (&s3v1alpha1.S3BucketAccess{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "S3BucketAccess")
+			os.Exit(1)
+		}
 	}
 	//+kubebuilder:scaffold:builder
 
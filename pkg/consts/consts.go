@@ -1,6 +1,10 @@
 package consts
 
-import v1 "k8s.io/api/core/v1"
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
 
 type CustomError string
 
@@ -9,23 +13,95 @@ func (e CustomError) Error() string { return string(e) }
 const (
 	LabelTeam = "snappcloud.io/team"
 
+	// AnnotationRotateKeys triggers a key rotation of an S3UserClaim whenever its value changes
+	AnnotationRotateKeys = "s3.snappcloud.io/rotate-keys"
+	// AnnotationPaused stops the provisioning of an S3Bucket, e.g. while its bucket is being migrated
+	AnnotationPaused = "s3.snappcloud.io/paused"
+	// AnnotationGeneratedBucketName persists the generated name of the bucket of an S3Bucket, so that it survives a
+	// restore of the S3Bucket without its status
+	AnnotationGeneratedBucketName = "s3.snappcloud.io/generated-bucket-name"
+	// LabelUserMigration marks the objects created by a UserMigration with its name
+	LabelUserMigration = "s3.snappcloud.io/user-migration"
+
+	DefaultKeyRotationGracePeriod = time.Hour
+	// MetricsCollectTimeout bounds the reads of the objects which the metrics are collected from on each scrape
+	MetricsCollectTimeout = 10 * time.Second
+	// RgwRetryBaseDelay is the delay before the first retry of a failed request to RGW, which doubles on each retry
+	RgwRetryBaseDelay = 100 * time.Millisecond
+	// UserMigrationPollInterval is the interval which a UserMigration checks the progress of other controllers at
+	UserMigrationPollInterval = 5 * time.Second
+
 	ResourceNameS3MaxObjects v1.ResourceName = "s3/objects"
 	ResourceNameS3MaxSize    v1.ResourceName = "s3/size"
 	ResourceNameS3MaxBuckets v1.ResourceName = "s3/buckets"
 
-	QuotaTypeUser = "user"
+	QuotaTypeUser   = "user"
+	QuotaTypeBucket = "bucket"
 
 	DataKeyAccessKey = "accessKey"
 	DataKeySecretKey = "secretKey"
+	DataKeyCABundle  = "ca.crt"
 
 	CephKeyTypeS3 = "s3"
+	// CephAccessKeyLength and CephSecretKeyLength are the lengths of the keys generated for a rotation, which match
+	// the keys generated by RGW
+	CephAccessKeyLength = 20
+	CephSecretKeyLength = 40
 
-	ErrExceededClusterQuota        = CustomError("exceeded cluster quota")
-	ErrExceededNamespaceQuota      = CustomError("exceeded namespace quota")
-	ErrClusterQuotaNotDefined      = CustomError("cluster quota is not defined")
-	S3UserClassImmutableErrMessage = "s3UserClass is immutable"
-	S3UserRefImmutableErrMessage   = "s3UserRef is immutable"
-	S3UserRefNotFoundErrMessage    = "there is no s3UserClaim regarding the defined s3UserRef"
+	ErrExceededClusterQuota               = CustomError("exceeded cluster quota")
+	ErrExceededNamespaceQuota             = CustomError("exceeded namespace quota")
+	ErrExceededClusterUsage               = CustomError("actual usage exceeds cluster quota")
+	ErrExceededNamespaceUsage             = CustomError("actual usage exceeds namespace quota")
+	ErrClusterQuotaNotDefined             = CustomError("cluster quota is not defined")
+	ErrS3UserClassNotFound                = CustomError("s3UserClass not found")
+	ErrCephUserNotFound                   = CustomError("ceph user to adopt not found")
+	ErrCephUserNotAdoptable               = CustomError("ceph user is not adoptable")
+	ErrCephUserAlreadyBound               = CustomError("ceph user is already bound to another s3User")
+	ErrBucketNotFound                     = CustomError("bucket to import not found")
+	ErrBucketOwnedByAnotherS3User         = CustomError("bucket is owned by the ceph user of another s3User")
+	ErrBucketOwnedByAnotherTenant         = CustomError("bucket is owned by a tenant which the namespace can't import from")
+	ErrTargetClaimExists                  = CustomError("target s3UserClaim already exists")
+	ErrGrantNotFound                      = CustomError("the accepted grant doesn't exist or isn't granted to the namespace")
+	ErrGranteeNotFound                    = CustomError("grantee s3UserClaim not found")
+	ErrGranteeSubuserNotFound             = CustomError("grantee subuser is not defined in the s3UserClaim")
+	ErrGranteeClassMismatch               = CustomError("grantee s3UserClaim belongs to another s3UserClass")
+	ErrS3BucketNotFound                   = CustomError("s3Bucket of the grant not found")
+	ErrPublicAccessNotAllowed             = CustomError("public access is not allowed for the namespace")
+	ExistingUserImmutableErrMessage       = "existingUser is immutable"
+	ExistingUserFormatErrMessage          = "existingUser must be in the form of [tenant$]user"
+	ExistingUserTenantErrMessage          = "existingUser must be in the tenant of the namespace unless the s3UserClass allows the namespace to adopt users of other tenants"
+	ExistingUserAlreadyAdoptedErrMessage  = "existingUser is already adopted by another s3UserClaim"
+	S3UserClassImmutableErrMessage        = "s3UserClass is immutable"
+	S3UserRefImmutableErrMessage          = "s3UserRef is immutable"
+	ExistingBucketImmutableErrMessage     = "existingBucket is immutable"
+	ExistingBucketFormatErrMessage        = "existingBucket must be in the form of [tenant/]bucket"
+	BucketNameImmutableErrMessage         = "the name of the bucket is immutable"
+	BucketNameFormatErrMessage            = "the name of the bucket must follow the S3 bucket naming rules"
+	BucketNameConflictErrMessage          = "only one of bucketName, generateBucketName and existingBucket can be set"
+	BucketNameInUseErrMessage             = "the name of the bucket is used by another s3Bucket"
+	SubuserNotFoundErrMessage             = "subuser is not defined in the s3UserClaim"
+	PolicyStatementErrMessage             = "invalid policy statement"
+	PublicAccessNotAllowedErrMessage      = "public access is not allowed for the namespace by the s3UserClass"
+	PublicAccessPrefixesErrMessage        = "prefixes must be set in the read-prefixes mode and only in that mode"
+	PrefixErrMessage                      = "prefix must not be empty, begin with a slash or contain wildcards"
+	BucketAccessModeErrMessage            = "exactly one of grant and accept must be set"
+	BucketAccessImmutableErrMessage       = "the bucket and the grantee of a grant and the accepted grant are immutable"
+	S3BucketRefNotFoundErrMessage         = "there is no s3Bucket regarding the defined s3BucketRef"
+	GranteeNamespaceErrMessage            = "the grantee must be in another namespace"
+	SubuserStillBoundErrMessage           = "bound subusers can't be removed"
+	S3UserRefNotFoundErrMessage           = "there is no s3UserClaim regarding the defined s3UserRef"
+	BucketQuotaExceededErrMessage         = "bucket quota exceeds the quota of the s3UserClaim"
+	LifecycleRuleDuplicateIDErrMessage    = "lifecycle rule id must be unique"
+	LifecycleRuleNoActionErrMessage       = "lifecycle rule must have at least one action"
+	LifecycleTagKeyEmptyErrMessage        = "lifecycle filter tag key must not be empty"
+	LifecycleTransitionOrderErrMessage    = "lifecycle transitions must be in ascending order of days and before the expiration"
+	CORSOriginErrMessage                  = "origin must not be empty and must contain at most one wildcard"
+	SourceClaimNotFoundErrMessage         = "there is no s3UserClaim regarding the defined sourceClaim"
+	TargetNamespaceSameAsSourceErrMessage = "targetNamespace must differ from the namespace of the sourceClaim"
+	TargetNamespaceNotFoundErrMessage     = "targetNamespace doesn't exist"
+	UserMigrationImmutableErrMessage      = "the spec of a userMigration is immutable"
+	KeyRotationIntervalErrMessage         = "interval must be positive"
+	KeyRotationGracePeriodErrMessage      = "gracePeriod must not be negative and must be shorter than interval"
 
 	FinalizerPrefix             = "s3.snappcloud.io/"
 	S3UserClaimCleanupFinalizer = FinalizerPrefix + "cleanup-s3userclaim"
@@ -34,10 +110,69 @@ const (
 	DeletionPolicyDelete = "delete"
 	DeletionPolicyRetain = "retain"
 
+	// Deletion policies of the Ceph user of an s3UserClaim
+	UserDeletionPolicyDelete = "Delete"
+	UserDeletionPolicyRetain = "Retain"
+	UserDeletionPolicyOrphan = "Orphan"
+
 	SubuserTagCreate = "create"
 	SubuserTagRemove = "remove"
 
 	// Bucket Access Levels
-	BucketAccessRead  = "read"
-	BucketAccessWrite = "write"
+	BucketAccessRead      = "read"
+	BucketAccessWrite     = "write"
+	BucketAccessList      = "list"
+	BucketAccessWriteOnly = "writeonly"
+
+	// Team quota backends
+	TeamQuotaBackendS3TeamQuota = "s3TeamQuota"
+	TeamQuotaBackendOpenShift   = "openshift"
+	TeamQuotaBackendDisabled    = "disabled"
+
+	// Quota enforcement modes
+	QuotaModeRequested  = "requested"
+	QuotaModeOvercommit = "overcommit"
+
+	// Public access modes of buckets
+	PublicAccessModeNone         = "none"
+	PublicAccessModeReadObjects  = "read-objects"
+	PublicAccessModeReadPrefixes = "read-prefixes"
+
+	// Status condition types
+	ConditionTypeReady                  = "Ready"
+	ConditionTypeS3UserClassResolved    = "S3UserClassResolved"
+	ConditionTypeCephUserSynced         = "CephUserSynced"
+	ConditionTypeQuotaSynced            = "QuotaSynced"
+	ConditionTypeSubusersSynced         = "SubusersSynced"
+	ConditionTypeSecretsSynced          = "SecretsSynced"
+	ConditionTypeKeyRotationSynced      = "KeyRotationSynced"
+	ConditionTypeS3UserSynced           = "S3UserSynced"
+	ConditionTypeBucketSynced           = "BucketSynced"
+	ConditionTypeBucketPolicySynced     = "BucketPolicySynced"
+	ConditionTypeBucketQuotaSynced      = "BucketQuotaSynced"
+	ConditionTypeBucketVersioningSynced = "BucketVersioningSynced"
+	ConditionTypeBucketLifecycleSynced  = "BucketLifecycleSynced"
+	ConditionTypeBucketCORSSynced       = "BucketCORSSynced"
+
+	// Event reasons
+	EventReasonUserCreated     = "UserCreated"
+	EventReasonQuotaChanged    = "QuotaChanged"
+	EventReasonSubuserAdded    = "SubuserAdded"
+	EventReasonSubuserRemoved  = "SubuserRemoved"
+	EventReasonSecretWritten   = "SecretWritten"
+	EventReasonBucketCreated   = "BucketCreated"
+	EventReasonPolicyApplied   = "PolicyApplied"
+	EventReasonDeletionBlocked = "DeletionBlocked"
+	EventReasonSyncFailed      = "SyncFailed"
+	EventReasonQuotaThreshold  = "QuotaThresholdReached"
+
+	// Status condition reasons
+	ConditionReasonSynced             = "Synced"
+	ConditionReasonSyncFailed         = "SyncFailed"
+	ConditionReasonProvisioned        = "Provisioned"
+	ConditionReasonProvisioningFailed = "ProvisioningFailed"
+	ConditionReasonDeletionFailed     = "DeletionFailed"
+	ConditionReasonRetained           = "Retained"
+	ConditionReasonAccepted           = "Accepted"
+	ConditionReasonPendingAcceptance  = "PendingAcceptance"
 )